@@ -0,0 +1,245 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKind is an error definition describing a value kind that cannot
+// be decoded.
+var ErrUnknownKind = errors.New("unknown value kind")
+
+// knownErrors is the set of errors that are preserved by identity when
+// decoded. Errors not in the set are decoded by their message.
+var knownErrors = []error{ErrComputationHalted, ErrIdentityUnknown, ErrOutOfGas}
+
+// Kind represents the kind of value held by a parameter.
+type Kind int
+
+const (
+	// NoneKind is the kind of an empty abstract value.
+	NoneKind Kind = iota
+
+	// IntKind is the kind of a parameter holding only an int value.
+	IntKind
+
+	// BytesKind is the kind of a parameter holding a byte value.
+	BytesKind
+
+	// ComputationKind is the kind of a parameter holding a computation.
+	ComputationKind
+
+	// NodeKind is the kind of a parameter holding a reference to a node.
+	NodeKind
+
+	// ErrorKind is the kind of a parameter holding an error (including the
+	// identity request).
+	ErrorKind
+)
+
+// kindNames maps each kind to its textual representation.
+var kindNames = map[Kind]string{
+	NoneKind:        "none",
+	IntKind:         "int",
+	BytesKind:       "bytes",
+	ComputationKind: "computation",
+	NodeKind:        "node",
+	ErrorKind:       "error",
+}
+
+// Abstract is a tagged union holding the abstract value of a parameter. Only
+// the field corresponding to the value's kind is set.
+type Abstract struct {
+	Kind Kind // the kind of the abstract value
+
+	Computation *Computation // the computation (ComputationKind)
+
+	Node *Node // the referenced node (NodeKind)
+
+	Error error // the error (ErrorKind)
+}
+
+// abstractJSON is the JSON representation of an abstract value.
+type abstractJSON struct {
+	Kind Kind `json:"kind"` // the kind of the abstract value
+
+	Computation *Computation `json:"computation,omitempty"` // the computation
+
+	Node *Node `json:"node,omitempty"` // the referenced node, by value
+
+	Error string `json:"error,omitempty"` // the error's message
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewComputationParameter initializes a new abstract parameter with the given
+// computation.
+func NewComputationParameter(comp Computation) Parameter {
+	return Parameter{
+		A: Abstract{
+			Kind:        ComputationKind, // Set the kind of the abstract value
+			Computation: &comp,           // Set the abstract value of the param to the computation
+		},
+	} // Return the parameter
+}
+
+// NewNodeParameter initializes a new abstract parameter referencing the given
+// node.
+func NewNodeParameter(node *Node) Parameter {
+	return Parameter{
+		A: Abstract{
+			Kind: NodeKind, // Set the kind of the abstract value
+			Node: node,     // Set the abstract value of the param to the node
+		},
+	} // Return the parameter
+}
+
+// String gets the textual representation of the kind.
+func (kind Kind) String() string {
+	// Check the kind has a name
+	if name, ok := kindNames[kind]; ok {
+		return name // Return the name
+	}
+
+	return fmt.Sprintf("kind(%d)", int(kind)) // Return the numeric representation
+}
+
+// MarshalText marshals the kind to its textual representation.
+func (kind Kind) MarshalText() ([]byte, error) {
+	// Check the kind has a name
+	if _, ok := kindNames[kind]; !ok {
+		return nil, ErrUnknownKind // Return the error
+	}
+
+	return []byte(kind.String()), nil // Return the name
+}
+
+// UnmarshalText unmarshals a kind from its textual representation.
+func (kind *Kind) UnmarshalText(b []byte) error {
+	// Iterate through the named kinds
+	for k, name := range kindNames {
+		// Check the name matches
+		if name == string(b) {
+			*kind = k // Set the kind
+
+			return nil // No error occurred, return nil
+		}
+	}
+
+	return ErrUnknownKind // Return the error
+}
+
+// IsNone checks whether or not the abstract value is empty.
+func (a *Abstract) IsNone() bool {
+	return a.Kind == NoneKind // Return whether or not the value is empty
+}
+
+// Equals checks whether or not two abstract values are equivalent.
+// Computations are compared by value, nodes by reference, and errors by
+// message.
+func (a *Abstract) Equals(b *Abstract) bool {
+	// Check the kinds differ
+	if a.Kind != b.Kind {
+		return false // The values can't be equivalent
+	}
+
+	// Handle the different kinds
+	switch a.Kind {
+	case ComputationKind:
+		// Check either of the computations is missing
+		if a.Computation == nil || b.Computation == nil {
+			return a.Computation == b.Computation // Only equal if both are missing
+		}
+
+		return a.Computation.Type == b.Computation.Type && a.Computation.Parameter.Equals(&b.Computation.Parameter) // Return whether or not the computations are equivalent
+	case NodeKind:
+		return a.Node == b.Node // Return whether or not the values refer to the same node
+	case ErrorKind:
+		return errorMessage(a.Error) == errorMessage(b.Error) // Return whether or not the errors are equivalent
+	default:
+		return true // Both values are empty
+	}
+}
+
+// MarshalJSON marshals the abstract value to a JSON byte slice. Referenced
+// nodes are marshalled by value.
+func (a Abstract) MarshalJSON() ([]byte, error) {
+	// Check the kind is unknown
+	if _, ok := kindNames[a.Kind]; !ok {
+		return nil, ErrUnknownKind // Return the error
+	}
+
+	aux := abstractJSON{Kind: a.Kind} // Get the JSON representation of the value
+
+	// Handle the different kinds
+	switch a.Kind {
+	case ComputationKind:
+		aux.Computation = a.Computation // Set the computation
+	case NodeKind:
+		aux.Node = a.Node // Set the node
+	case ErrorKind:
+		aux.Error = errorMessage(a.Error) // Set the error's message
+	}
+
+	return json.Marshal(aux) // Marshal the value to JSON
+}
+
+// UnmarshalJSON unmarshals an abstract value from a given JSON byte slice.
+func (a *Abstract) UnmarshalJSON(b []byte) error {
+	var aux abstractJSON // Get a buffer to unmarshal the JSON representation into
+
+	err := json.Unmarshal(b, &aux) // Unmarshal the JSON
+	if err != nil {                // Check for errors
+		return err // Return the error
+	}
+
+	*a = Abstract{Kind: aux.Kind} // Reset the value
+
+	// Handle the different kinds
+	switch aux.Kind {
+	case NoneKind:
+	case ComputationKind:
+		a.Computation = aux.Computation // Set the computation
+	case NodeKind:
+		a.Node = aux.Node // Set the node
+	case ErrorKind:
+		a.Error = ErrorFromMessage(aux.Error) // Set the error
+	default:
+		return ErrUnknownKind // Abstract values can't hold ints or bytes
+	}
+
+	return nil // No error occurred, return nil
+}
+
+// ErrorFromMessage gets the error that can be held by a parameter with the
+// given message, or a new error with the given message if none exists.
+func ErrorFromMessage(msg string) error {
+	// Iterate through the known errors
+	for _, err := range knownErrors {
+		// Check the messages match
+		if err.Error() == msg {
+			return err // Return the known error
+		}
+	}
+
+	return errors.New(msg) // Return a new error
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// errorMessage gets the message of the given error, or an empty string if the
+// error is nil.
+func errorMessage(err error) string {
+	// Check no error
+	if err == nil {
+		return "" // Return an empty message
+	}
+
+	return err.Error() // Return the error's message
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,269 @@
+// Package analysis implements static analysis of activation nets.
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dowlandaiello/eve/activation"
+)
+
+// outcome is a set of possible outcomes of comparing a value against a
+// comparator. Since a value may be equal to a comparator by its abstract
+// value while still being ordered before or after it, values ordered before
+// or after a comparator are split by whether or not they are also equal to
+// it.
+type outcome int
+
+const (
+	// less is the outcome of a value ordered before, and not equal to, the
+	// comparator.
+	less outcome = 1 << iota
+
+	// lessButEqual is the outcome of a value ordered before, but equal by
+	// abstract value to, the comparator.
+	lessButEqual
+
+	// equal is the outcome of a value ordered neither before nor after the
+	// comparator.
+	equal
+
+	// greater is the outcome of a value ordered after, and not equal to, the
+	// comparator.
+	greater
+
+	// greaterButEqual is the outcome of a value ordered after, but equal by
+	// abstract value to, the comparator.
+	greaterButEqual
+)
+
+// outcomes maps each condition to the outcomes under which it activates.
+var outcomes = map[activation.Condition]outcome{
+	activation.EqualTo:              equal | lessButEqual | greaterButEqual,
+	activation.NotEqualTo:           less | greater,
+	activation.LessThan:             less | lessButEqual,
+	activation.LessThanOrEqualTo:    less | lessButEqual | equal | greaterButEqual,
+	activation.GreaterThan:          greater | greaterButEqual,
+	activation.GreaterThanOrEqualTo: greater | greaterButEqual | equal | lessButEqual,
+	activation.Unconditional:        less | lessButEqual | equal | greater | greaterButEqual,
+}
+
+// Analyzer analyzes activation nets, naming operations according to its
+// operation registry.
+type Analyzer struct {
+	Operations *activation.OperationRegistry // the operations used to name computations
+}
+
+// LinkSite identifies a link in a net by the path taken to reach it from one
+// of the net's root nodes.
+type LinkSite struct {
+	Root int // the index of the root node from which the link descends
+
+	Path []int // the indices of the links followed from the root node to the link's node
+
+	Link int // the index of the link in its node
+}
+
+// Report is the result of an analysis of a net. A node is reachable if it is
+// a live root node, or the destination of a reachable link; a link is
+// reachable if it belongs to a reachable node, and there is some value under
+// which it would be followed.
+type Report struct {
+	Nodes int // the number of nodes in the net, including dead and unreachable nodes
+
+	LiveNodes int // the number of nodes in the net that are alive
+
+	ReachableNodes int // the number of nodes that can be executed
+
+	ReachableLinks int // the number of links that can be followed
+
+	MaxDepth int // the depth of the deepest reachable node, where root nodes have a depth of one
+
+	UnreachableLinks []LinkSite // the live links of reachable nodes that can never be followed
+
+	Operations map[string]int // the number of reachable nodes executing each operation
+
+	Complexity int // the functional complexity of the net
+}
+
+// analysis holds the state of a single analysis.
+type analysis struct {
+	operations *activation.OperationRegistry // the operations used to name computations
+
+	fanOut bool // whether or not the net's nodes follow every satisfied link
+
+	report Report // the report being built
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewAnalyzer initializes a new analyzer with the given operations.
+func NewAnalyzer(operations *activation.OperationRegistry) Analyzer {
+	return Analyzer{
+		Operations: operations, // Set the analyzer's operations
+	} // Return the initialized analyzer
+}
+
+// Analyze analyzes the given net, using the default operations.
+func Analyze(net activation.Net) Report {
+	return NewAnalyzer(activation.DefaultOperations).Analyze(net) // Analyze the net
+}
+
+// Analyze analyzes the given net. Links are found to be unreachable when
+// they have an invalid condition, or, unless the net's nodes fan out, when
+// every value that would satisfy their condition would also satisfy the
+// condition of an earlier live link with a destination in the same node (e.g.
+// a link with the >= condition following links with the > and == conditions
+// on the same comparator). Only disabled and unknown operations are excluded
+// from the operations used.
+func (analyzer Analyzer) Analyze(net activation.Net) Report {
+	a := analysis{
+		operations: analyzer.Operations,                  // Set the analysis' operations
+		fanOut:     net.LinkMode.IsFanOut(),              // Set whether or not the net's nodes fan out
+		report:     Report{Operations: map[string]int{}}, // Initialize the report
+	} // Initialize the analysis' state
+
+	// Iterate through the net's root nodes
+	for i := range net.RootNodes {
+		a.node(&net.RootNodes[i], net.RootNodes[i].Alive, 1, LinkSite{Root: i}) // Analyze the root node
+	}
+
+	a.report.Complexity = a.report.ReachableNodes + a.report.ReachableLinks + len(a.report.Operations) // Score the net's functional complexity
+
+	return a.report // Return the report
+}
+
+// Complexity gets the functional complexity of the given net, using the
+// given operations. The functional complexity of a net is the sum of the
+// number of its reachable nodes, the number of its reachable links, and the
+// number of distinct operations executed by its reachable nodes. Nets that
+// can't execute anything have a functional complexity of zero.
+func Complexity(operations *activation.OperationRegistry, net activation.Net) int {
+	return NewAnalyzer(operations).Analyze(net).Complexity // Return the net's functional complexity
+}
+
+// String gets the textual representation of the link site, as the path of
+// indices taken from the net's root nodes.
+func (site LinkSite) String() string {
+	s := fmt.Sprintf("root %d", site.Root) // Start with the root node
+
+	// Iterate through the links followed to the site's node
+	for _, i := range site.Path {
+		s += fmt.Sprintf(" -> link %d", i) // Add the link
+	}
+
+	return s + fmt.Sprintf(" -> link %d", site.Link) // Return the site
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// node analyzes the given node at the given depth, along with each of its
+// descendants. The given site holds the path taken to reach the node.
+func (a *analysis) node(node *activation.Node, reachable bool, depth int, site LinkSite) {
+	a.report.Nodes++ // Count the node
+
+	// Check the node is alive
+	if node.Alive {
+		a.report.LiveNodes++ // Count the live node
+	}
+
+	// Check the node is reachable
+	if reachable {
+		a.report.ReachableNodes++ // Count the reachable node
+
+		// Check the node is the deepest yet
+		if depth > a.report.MaxDepth {
+			a.report.MaxDepth = depth // Set the max depth
+		}
+
+		// Check the node's operation can be executed
+		if def, ok := a.operations.Lookup(node.Function.Type); ok && def.Enabled {
+			a.report.Operations[def.Name]++ // Count the operation
+		}
+	}
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		link := &node.Links[i] // Get a reference to the link
+
+		// Check the link has no destination
+		if !link.HasDestination() {
+			continue // Continue
+		}
+
+		taken := reachable && link.Alive // Get whether or not the link can be followed
+
+		// Check the link is live, and the node is reachable
+		if taken && a.shadowed(node.Links[:i], link) {
+			taken = false // The link can never be followed
+
+			a.report.UnreachableLinks = append(a.report.UnreachableLinks, LinkSite{Root: site.Root, Path: append([]int(nil), site.Path...), Link: i}) // Record the unreachable link
+		}
+
+		// Check the link can be followed
+		if taken {
+			a.report.ReachableLinks++ // Count the reachable link
+		}
+
+		a.node(&link.Destination, taken, depth+1, LinkSite{Root: site.Root, Path: append(append([]int(nil), site.Path...), i)}) // Analyze the link's destination
+	}
+}
+
+// shadowed checks whether or not the given link can never be followed, since
+// either its condition is invalid, or every value satisfying its condition
+// satisfies the condition of one of the given preceding links. Since nodes
+// that fan out follow every satisfied link, the preceding links of such nodes
+// are disregarded.
+func (a *analysis) shadowed(preceding []activation.ConditionalLink, link *activation.ConditionalLink) bool {
+	remaining := possibleOutcomes(link) // Get the outcomes under which the link would be followed
+
+	// Check the net's nodes fan out
+	if a.fanOut {
+		return remaining == 0 // Return whether or not the link's condition is invalid
+	}
+
+	// Iterate through the preceding links
+	for i := range preceding {
+		// Check the preceding link would never be followed in place of the link
+		if !preceding[i].Alive || !preceding[i].HasDestination() {
+			continue // Continue
+		}
+
+		// Check the preceding link is unconditional
+		if preceding[i].Condition == activation.Unconditional {
+			return true // The preceding link is always followed
+		}
+
+		// Check the links share a comparator
+		if sameComparator(&preceding[i].Comparator, &link.Comparator) {
+			remaining &^= outcomes[preceding[i].Condition] // Remove the outcomes claimed by the preceding link
+		}
+	}
+
+	return remaining == 0 // Return whether or not any outcomes remain
+}
+
+// possibleOutcomes gets the outcomes under which the given link would be
+// followed. Since values can only be equal to comparators without abstract
+// values by their int and byte values, comparisons against such comparators
+// can't be split by abstract value.
+func possibleOutcomes(link *activation.ConditionalLink) outcome {
+	o := outcomes[link.Condition] // Get the outcomes satisfying the link's condition
+
+	// Check the comparator has no abstract value
+	if link.Comparator.A.IsNone() {
+		o &^= lessButEqual | greaterButEqual // Values can't be equal by abstract value
+	}
+
+	return o // Return the outcomes
+}
+
+// sameComparator checks whether or not two comparators are identical, such
+// that any value compares the same way against both of them.
+func sameComparator(a, b *activation.Parameter) bool {
+	return a.I == b.I && bytes.Equal(a.B, b.B) && a.A.Equals(&b.A) // Return whether or not the comparators are identical
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,286 @@
+// Package asm implements a textual assembly language for activation nets.
+//
+// A net is written as a list of root nodes, each of which is written as its
+// function followed by its links:
+//
+//	# comments run to the end of the line
+//	net reducer=sum {
+//		node add 3 {
+//			link == 3 -> node mul 0x0a0b {}
+//			link dead always 0 -> none
+//		}
+//		node dead identity (0 0x fn(sub -1)) {}
+//	}
+//
+// A net's header may also set its link mode (links=first, ordered, or
+// parallel), and the combiner merging the branches of fanned out nodes (e.g.
+// net reducer=sum links=parallel combiner=max). Nodes and links are alive
+// unless marked dead. Operations are written by their registered names (or as
+// op<n> if unnamed), and conditions as one of ==, !=, <, <=, >, >=, or
+// always. A link's destination is either a node or none.
+//
+// A parameter is written as an int (3), as bytes in hex (0x0a0b), as an
+// abstract value, or as a tuple of all three ((3 0x0a0b fn(add 1))). Abstract
+// values are written as fn(<operation> <parameter>) for computations,
+// ref(<node>) for node references, and err("<message>") for errors.
+package asm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/dowlandaiello/eve/activation"
+)
+
+// conditionNames maps each condition to its textual representation.
+var conditionNames = map[activation.Condition]string{
+	activation.EqualTo:              "==",
+	activation.NotEqualTo:           "!=",
+	activation.LessThan:             "<",
+	activation.LessThanOrEqualTo:    "<=",
+	activation.GreaterThan:          ">",
+	activation.GreaterThanOrEqualTo: ">=",
+	activation.Unconditional:        "always",
+}
+
+// Assembler parses and prints activation nets, naming operations according
+// to its operation registry.
+type Assembler struct {
+	Operations *activation.OperationRegistry // the operations used to name computations
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewAssembler initializes a new assembler with the given operations.
+func NewAssembler(operations *activation.OperationRegistry) Assembler {
+	return Assembler{
+		Operations: operations, // Set the assembler's operations
+	} // Return the initialized assembler
+}
+
+// Parse parses a net from the given source, using the default operations.
+func Parse(src string) (activation.Net, error) {
+	return NewAssembler(activation.DefaultOperations).Parse(src) // Parse the net
+}
+
+// Print prints the given net, using the default operations.
+func Print(net activation.Net) string {
+	return NewAssembler(activation.DefaultOperations).Print(net) // Print the net
+}
+
+// Parse parses a net from the given source.
+func (assembler Assembler) Parse(src string) (activation.Net, error) {
+	p := parser{
+		lexer:      newLexer(src),        // Set the parser's lexer
+		operations: assembler.Operations, // Set the parser's operations
+	} // Initialize a parser
+
+	return p.parse() // Parse the net
+}
+
+// Print prints the given net. Printing a parsed net yields source that
+// parses to the same net.
+func (assembler Assembler) Print(net activation.Net) string {
+	var buf bytes.Buffer // Get a buffer to print the net into
+
+	fmt.Fprintf(&buf, "net reducer=%s", net.Reducer) // Print the net's header
+
+	// Check the net doesn't use the default link mode
+	if net.LinkMode != activation.FirstMatch {
+		fmt.Fprintf(&buf, " links=%s", net.LinkMode) // Print the net's link mode
+	}
+
+	// Check the net doesn't use the default combiner
+	if net.Combiner != activation.LastReducer {
+		fmt.Fprintf(&buf, " combiner=%s", net.Combiner) // Print the net's combiner
+	}
+
+	buf.WriteString(" {\n") // Open the net
+
+	// Iterate through the net's root nodes
+	for _, node := range net.RootNodes {
+		buf.WriteString("  ")              // Indent the root node
+		assembler.printNode(&buf, node, 1) // Print the root node
+		buf.WriteString("\n")              // End the root node
+	}
+
+	buf.WriteString("}\n") // Close the net
+
+	return buf.String() // Return the printed net
+}
+
+// FormatComputation formats the given computation as it would appear in a
+// printed net.
+func (assembler Assembler) FormatComputation(comp activation.Computation) string {
+	var buf bytes.Buffer // Get a buffer to print the computation into
+
+	assembler.printComputation(&buf, comp) // Print the computation
+
+	return buf.String() // Return the printed computation
+}
+
+// FormatParameter formats the given parameter as it would appear in a printed
+// net.
+func (assembler Assembler) FormatParameter(param activation.Parameter) string {
+	var buf bytes.Buffer // Get a buffer to print the parameter into
+
+	assembler.printParameter(&buf, param, 0) // Print the parameter
+
+	return buf.String() // Return the printed parameter
+}
+
+// FormatCondition formats the given condition as it would appear in a printed
+// net.
+func FormatCondition(condition activation.Condition) string {
+	// Check the condition has a name
+	if name, ok := conditionNames[condition]; ok {
+		return name // Return the name
+	}
+
+	return fmt.Sprintf("cond%d", int(condition)) // Return the condition's number
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// printNode prints the given node at the given depth of indentation.
+func (assembler Assembler) printNode(buf *bytes.Buffer, node activation.Node, depth int) {
+	buf.WriteString("node ") // Print the keyword
+
+	// Check the node is dead
+	if !node.Alive {
+		buf.WriteString("dead ") // Mark the node as dead
+	}
+
+	assembler.printComputation(buf, node.Function) // Print the node's function
+
+	// Check the node has no links
+	if len(node.Links) == 0 {
+		buf.WriteString(" {}") // Print an empty body
+
+		return // Done
+	}
+
+	buf.WriteString(" {\n") // Open the body
+
+	// Iterate through the node's links
+	for _, link := range node.Links {
+		indent(buf, depth+1)                    // Indent the link
+		assembler.printLink(buf, link, depth+1) // Print the link
+		buf.WriteString("\n")                   // End the link
+	}
+
+	indent(buf, depth)   // Indent the closing brace
+	buf.WriteString("}") // Close the body
+}
+
+// printLink prints the given link at the given depth of indentation.
+func (assembler Assembler) printLink(buf *bytes.Buffer, link activation.ConditionalLink, depth int) {
+	buf.WriteString("link ") // Print the keyword
+
+	// Check the link is dead
+	if !link.Alive {
+		buf.WriteString("dead ") // Mark the link as dead
+	}
+
+	buf.WriteString(FormatCondition(link.Condition))      // Print the condition
+	buf.WriteString(" ")                                  // Separate the condition from the comparator
+	assembler.printParameter(buf, link.Comparator, depth) // Print the comparator
+	buf.WriteString(" -> ")                               // Separate the comparator from the destination
+
+	// Check the link has no destination
+	if link.Destination.IsNone() {
+		buf.WriteString("none") // Print the lack of a destination
+
+		return // Done
+	}
+
+	assembler.printNode(buf, link.Destination, depth) // Print the destination
+}
+
+// printComputation prints the given computation.
+func (assembler Assembler) printComputation(buf *bytes.Buffer, comp activation.Computation) {
+	// Check the operation has a name
+	if def, ok := assembler.Operations.Lookup(comp.Type); ok {
+		buf.WriteString(def.Name) // Print the operation's name
+	} else {
+		fmt.Fprintf(buf, "op%d", int(comp.Type)) // Print the operation's number
+	}
+
+	buf.WriteString(" ")                             // Separate the operation from its parameter
+	assembler.printParameter(buf, comp.Parameter, 0) // Print the parameter
+}
+
+// printParameter prints the given parameter. Node references are printed
+// at the given depth of indentation.
+func (assembler Assembler) printParameter(buf *bytes.Buffer, param activation.Parameter, depth int) {
+	hasInt, hasBytes, hasAbstract := param.I != 0, len(param.B) > 0, !param.A.IsNone() // Get which of the parameter's values are set
+
+	// Handle the different combinations of values
+	switch {
+	case !hasBytes && !hasAbstract:
+		buf.WriteString(strconv.Itoa(param.I)) // Print the int
+	case !hasInt && !hasAbstract:
+		buf.WriteString("0x" + hex.EncodeToString(param.B)) // Print the bytes
+	case !hasInt && !hasBytes:
+		assembler.printAbstract(buf, param.A, depth) // Print the abstract value
+	default:
+		fmt.Fprintf(buf, "(%d 0x%s", param.I, hex.EncodeToString(param.B)) // Print the int and bytes
+
+		// Check the parameter has an abstract value
+		if hasAbstract {
+			buf.WriteString(" ")                         // Separate the bytes from the abstract value
+			assembler.printAbstract(buf, param.A, depth) // Print the abstract value
+		}
+
+		buf.WriteString(")") // Close the tuple
+	}
+}
+
+// printAbstract prints the given abstract value. Node references are printed
+// at the given depth of indentation.
+func (assembler Assembler) printAbstract(buf *bytes.Buffer, a activation.Abstract, depth int) {
+	// Handle the different kinds
+	switch a.Kind {
+	case activation.ComputationKind:
+		buf.WriteString("fn(") // Open the computation
+
+		// Check the computation exists
+		if a.Computation != nil {
+			assembler.printComputation(buf, *a.Computation) // Print the computation
+		}
+
+		buf.WriteString(")") // Close the computation
+	case activation.NodeKind:
+		buf.WriteString("ref(") // Open the reference
+
+		// Check the node exists
+		if a.Node != nil {
+			assembler.printNode(buf, *a.Node, depth) // Print the referenced node
+		}
+
+		buf.WriteString(")") // Close the reference
+	case activation.ErrorKind:
+		msg := "" // Get the error's message
+
+		// Check the error exists
+		if a.Error != nil {
+			msg = a.Error.Error() // Set the message
+		}
+
+		fmt.Fprintf(buf, "err(%s)", strconv.Quote(msg)) // Print the error
+	}
+}
+
+// indent writes two spaces for each level of the given depth.
+func indent(buf *bytes.Buffer, depth int) {
+	// Write each level of indentation
+	for i := 0; i < depth; i++ {
+		buf.WriteString("  ") // Write a level of indentation
+	}
+}
+
+/* END INTERNAL METHODS */
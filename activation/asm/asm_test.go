@@ -0,0 +1,103 @@
+package asm
+
+import (
+	"testing"
+
+	"github.com/dowlandaiello/eve/activation"
+)
+
+// example is the net given in the package documentation.
+const example = `# comments run to the end of the line
+net reducer=sum {
+	node add 3 {
+		link == 3 -> node mul 0x0a0b {}
+		link dead always 0 -> none
+	}
+	node dead identity (0 0x fn(sub -1)) {}
+}`
+
+// TestRoundTrip checks that parsing a printed net yields the same net, and
+// that printing it again yields the same source.
+func TestRoundTrip(t *testing.T) {
+	leaf := activation.NewNode(activation.NewComputation(activation.Multiply, activation.Parameter{I: -2}), nil) // Make a node without links
+
+	fanned := activation.NewNet([]activation.Node{
+		activation.NewNode(activation.NewComputation(activation.Inject, activation.NewNodeParameter(&leaf)), []activation.ConditionalLink{
+			activation.NewConditionalLink(activation.LessThanOrEqualTo, activation.Parameter{I: 1, B: []byte{2}, A: activation.NewErrorParameter(activation.ErrOutOfGas).A}, leaf), // Compare against a tuple
+			activation.NewConditionalLink(activation.Condition(9), activation.NewErrorParameter(activation.ErrIdentityUnknown), activation.Node{}),                                 // Compare with an unnamed condition
+		}),
+		activation.NewNode(activation.NewComputation(activation.Operation(42), activation.Parameter{A: activation.Abstract{Kind: activation.NodeKind}}), nil), // Use an unnamed operation
+	}) // Make a net using each of the forms of nodes, links, and parameters
+	fanned.Reducer = activation.ConcatReducer    // Concatenate the outputs of the root nodes
+	fanned.LinkMode = activation.OrderedFanOut   // Follow each of the satisfied links
+	fanned.Combiner = activation.MajorityReducer // Take the most common output of the branches
+
+	parsed, err := Parse(example) // Parse the documented net
+	if err != nil {               // Check for errors
+		t.Fatal(err) // Fail
+	}
+
+	nets := []activation.Net{{}, parsed, fanned} // Get the nets to print
+
+	// Iterate through the seeds of the random nets to print
+	for seed := int64(0); seed < 200; seed++ {
+		nets = append(nets, activation.RandomNet(activation.NewEnvironment(seed))) // Add the random net
+	}
+
+	// Iterate through the nets
+	for i, net := range nets {
+		src := Print(net) // Print the net
+
+		reparsed, err := Parse(src) // Parse the printed net
+		if err != nil {             // Check for errors
+			t.Fatalf("net %d: %v in:\n%s", i, err, src) // Fail
+		}
+
+		// Check the parsed net differs
+		if !reparsed.DeepEquals(&net) {
+			t.Fatalf("net %d: parsed net differs from printed net:\n%s", i, src) // Fail
+		}
+
+		// Check the parsed net prints differently
+		if reprinted := Print(reparsed); reprinted != src {
+			t.Fatalf("net %d: printed\n%s\nthen\n%s", i, src, reprinted) // Fail
+		}
+	}
+}
+
+// TestParseErrors checks that malformed source is rejected with errors
+// pointing at the offending token.
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string // the name of the test
+		src  string // the source to parse
+		err  string // the expected error
+	}{
+		{"empty", "", `1:1: expected "net", found end of input`},
+		{"unknown option", "net depth=deep {}", `1:5: unknown net option: depth`},
+		{"unknown reducer", "net reducer=min {}", `1:13: unknown reducer: min`},
+		{"unknown operation", "net {\n\tnode frob 1 {}\n}", `2:7: unknown operation "frob"`},
+		{"unterminated net", "net {\n\tnode add 1 {}\n", `3:1: expected "node", found end of input`},
+		{"unterminated node", "net {\n\tnode add 1 {\n", `3:1: expected "link", found end of input`},
+		{"unknown condition", "net { node add 1 { link ~ 1 -> none } }", `1:25: unexpected character '~'`},
+		{"missing arrow", "net { node add 1 { link == 1 none } }", `1:30: expected "->", found "none"`},
+		{"ref without node", "net { node add ref(link) {} }", `1:20: expected "node", found "link"`},
+		{"unterminated ref", "net { node add ref(node add 1 {} {} }", `1:34: expected ")", found "{"`},
+		{"err without message", "net { node add err(3) {} }", `1:20: expected an error message, found "3"`},
+		{"empty err", "net { node add err() {} }", `1:16: expected a parameter, found "err"`},
+		{"unterminated err", "net { node add err(\"halted) {} }", `1:20: unterminated string`},
+		{"unknown parameter", "net { node add foo(1) {} }", `1:16: expected a parameter, found "foo"`},
+		{"tuple without bytes", "net { node add (1 fn(add 1)) {} }", `1:19: expected bytes, found "fn"`},
+		{"trailing source", "net {} net {}", `1:8: unexpected "net" after net`},
+	}
+
+	// Iterate through the tests
+	for _, test := range tests {
+		_, err := Parse(test.src) // Parse the source
+
+		// Check the source wasn't rejected with the expected error
+		if err == nil || err.Error() != test.err {
+			t.Fatalf("%s: got error %v, want %s", test.name, err, test.err) // Fail
+		}
+	}
+}
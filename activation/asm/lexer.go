@@ -0,0 +1,215 @@
+// Package asm implements a textual assembly language for activation nets.
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenType represents the type of a lexical token.
+type tokenType int
+
+const (
+	// eofToken is the token marking the end of the source.
+	eofToken tokenType = iota
+
+	// identToken is a word (e.g. a keyword or an operation name).
+	identToken
+
+	// intToken is a decimal integer literal.
+	intToken
+
+	// hexToken is a hexadecimal byte literal, including its 0x prefix.
+	hexToken
+
+	// stringToken is a quoted string literal.
+	stringToken
+
+	// symbolToken is a punctuation symbol (e.g. a brace or a condition).
+	symbolToken
+)
+
+// symbols is the set of punctuation symbols, longest first.
+var symbols = []string{"->", "==", "!=", "<=", ">=", "<", ">", "=", "{", "}", "(", ")"}
+
+// token is a single lexical token.
+type token struct {
+	Type tokenType // the type of the token
+
+	Text string // the text of the token (unquoted, for strings)
+
+	Line, Column int // the position of the token in the source
+}
+
+// lexer splits source into tokens.
+type lexer struct {
+	src string // the source being split
+
+	pos int // the current offset into the source
+
+	line, column int // the current position in the source
+}
+
+/* BEGIN INTERNAL METHODS */
+
+// newLexer initializes a new lexer for the given source.
+func newLexer(src string) *lexer {
+	return &lexer{
+		src:    src, // Set the source
+		line:   1,   // Start at the first line
+		column: 1,   // Start at the first column
+	} // Return the initialized lexer
+}
+
+// next reads the next token from the source.
+func (l *lexer) next() (token, error) {
+	l.skipSpace() // Skip any whitespace and comments
+
+	tok := token{Line: l.line, Column: l.column} // Start the token at the current position
+
+	// Check the source has been exhausted
+	if l.pos >= len(l.src) {
+		return tok, nil // Return the end of the source
+	}
+
+	rest := l.src[l.pos:] // Get the remaining source
+
+	// Handle the different kinds of tokens
+	switch c := rest[0]; {
+	case c == '"':
+		n := 1 // Get the length of the quoted string
+
+		// Consume each of the characters in the string
+		for n < len(rest) && rest[n] != '"' && rest[n] != '\n' {
+			// Check the character escapes the next character
+			if rest[n] == '\\' {
+				n++ // Consume the escaped character
+			}
+
+			n++ // Consume the character
+		}
+
+		// Check the string isn't terminated
+		if n >= len(rest) || rest[n] != '"' {
+			return tok, l.errorf(tok, "unterminated string") // Return the error
+		}
+
+		text, err := strconv.Unquote(rest[:n+1]) // Unquote the string
+		if err != nil {                          // Check for errors
+			return tok, l.errorf(tok, "invalid string: %v", err) // Return the error
+		}
+
+		tok.Type = stringToken // Set the type of the token
+		tok.Text = text        // Set the unquoted string
+		l.advance(n + 1)       // Consume the string
+	case strings.HasPrefix(rest, "0x"):
+		n := 2 + strings.IndexFunc(rest[2:]+" ", func(r rune) bool {
+			return !strings.ContainsRune("0123456789abcdefABCDEF", r)
+		}) // Get the length of the literal
+
+		tok.Type = hexToken // Set the type of the token
+		tok.Text = rest[:n] // Set the literal
+		l.advance(n)        // Consume the literal
+	case c == '-' && len(rest) > 1 && isDigit(rest[1]), isDigit(c):
+		n := 1 // Get the length of the literal
+
+		// Consume each of the digits
+		for n < len(rest) && isDigit(rest[n]) {
+			n++ // Consume the digit
+		}
+
+		tok.Type = intToken // Set the type of the token
+		tok.Text = rest[:n] // Set the literal
+		l.advance(n)        // Consume the literal
+	case isIdentStart(rune(c)):
+		n := strings.IndexFunc(rest, func(r rune) bool {
+			return !isIdentStart(r) && !unicode.IsDigit(r)
+		}) // Get the length of the word
+
+		// Check the word runs to the end of the source
+		if n < 0 {
+			n = len(rest) // Consume the remaining source
+		}
+
+		tok.Type = identToken // Set the type of the token
+		tok.Text = rest[:n]   // Set the word
+		l.advance(n)          // Consume the word
+	default:
+		// Iterate through the symbols
+		for _, symbol := range symbols {
+			// Check the source begins with the symbol
+			if strings.HasPrefix(rest, symbol) {
+				tok.Type = symbolToken // Set the type of the token
+				tok.Text = symbol      // Set the symbol
+				l.advance(len(symbol)) // Consume the symbol
+
+				return tok, nil // Return the symbol
+			}
+		}
+
+		return tok, l.errorf(tok, "unexpected character %q", c) // Return the error
+	}
+
+	return tok, nil // Return the token
+}
+
+// skipSpace consumes any whitespace and comments.
+func (l *lexer) skipSpace() {
+	// Consume characters until a token begins
+	for l.pos < len(l.src) {
+		c := l.src[l.pos] // Get the current character
+
+		// Check the character begins a comment
+		if c == '#' {
+			// Consume the rest of the line
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1) // Consume the character
+			}
+
+			continue // Continue
+		}
+
+		// Check the character isn't whitespace
+		if !unicode.IsSpace(rune(c)) {
+			return // Done
+		}
+
+		l.advance(1) // Consume the whitespace
+	}
+}
+
+// advance consumes the given number of bytes, keeping track of the current
+// line and column.
+func (l *lexer) advance(n int) {
+	// Consume each of the bytes
+	for i := 0; i < n; i++ {
+		// Check the byte ends a line
+		if l.src[l.pos] == '\n' {
+			l.line++     // Move to the next line
+			l.column = 1 // Move to the first column
+		} else {
+			l.column++ // Move to the next column
+		}
+
+		l.pos++ // Consume the byte
+	}
+}
+
+// errorf constructs an error at the position of the given token.
+func (l *lexer) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("%d:%d: %s", tok.Line, tok.Column, fmt.Sprintf(format, args...)) // Return the error
+}
+
+// isDigit checks whether or not the given character is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9' // Return whether or not the character is a digit
+}
+
+// isIdentStart checks whether or not the given character can begin a word.
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) // Return whether or not the character can begin a word
+}
+
+/* END INTERNAL METHODS */
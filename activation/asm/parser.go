@@ -0,0 +1,486 @@
+// Package asm implements a textual assembly language for activation nets.
+package asm
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/dowlandaiello/eve/activation"
+)
+
+// parser builds an activation net from a stream of tokens.
+type parser struct {
+	lexer *lexer // the lexer producing the parser's tokens
+
+	operations *activation.OperationRegistry // the operations used to resolve operation names
+
+	tok token // the current token
+
+	peeked bool // whether or not the current token has been read, but not consumed
+}
+
+/* BEGIN INTERNAL METHODS */
+
+// parse parses an entire net.
+func (p *parser) parse() (activation.Net, error) {
+	var net activation.Net // Get a buffer to parse the net into
+
+	err := p.expect(identToken, "net") // Parse the keyword
+	if err != nil {                    // Check for errors
+		return net, err // Return the error
+	}
+
+	// Parse each of the net's options
+	for {
+		tok, err := p.peek() // Get the next token
+		if err != nil {      // Check for errors
+			return net, err // Return the error
+		}
+
+		// Check the options have ended
+		if tok.Type != identToken {
+			break // Stop parsing options
+		}
+
+		p.peeked = false // Consume the option's name
+
+		err = p.expect(symbolToken, "=") // Parse the assignment
+		if err != nil {                  // Check for errors
+			return net, err // Return the error
+		}
+
+		value, err := p.expectType(identToken, "a value for "+tok.Text) // Parse the option's value
+		if err != nil {                                                 // Check for errors
+			return net, err // Return the error
+		}
+
+		// Handle the different options
+		switch tok.Text {
+		case "reducer":
+			net.Reducer, err = activation.ParseReducer(value.Text) // Resolve the reducer
+		case "links":
+			net.LinkMode, err = activation.ParseLinkMode(value.Text) // Resolve the link mode
+		case "combiner":
+			net.Combiner, err = activation.ParseReducer(value.Text) // Resolve the combiner
+		default:
+			return net, p.lexer.errorf(tok, "unknown net option: %s", tok.Text) // Return the error
+		}
+
+		// Check for errors
+		if err != nil {
+			return net, p.lexer.errorf(value, "%v: %s", err, value.Text) // Return the error
+		}
+	}
+
+	err = p.expect(symbolToken, "{") // Open the net
+	if err != nil {                  // Check for errors
+		return net, err // Return the error
+	}
+
+	// Parse each of the root nodes
+	for {
+		// Check the net has been closed
+		if ok, err := p.accept(symbolToken, "}"); err != nil || ok {
+			// Check for errors
+			if err != nil {
+				return net, err // Return the error
+			}
+
+			break // Stop parsing root nodes
+		}
+
+		node, err := p.parseNode() // Parse the root node
+		if err != nil {            // Check for errors
+			return net, err // Return the error
+		}
+
+		net.RootNodes = append(net.RootNodes, node) // Add the root node
+	}
+
+	tok, err := p.peek() // Get the token following the net
+	if err != nil {      // Check for errors
+		return net, err // Return the error
+	}
+
+	// Check the source continues past the net
+	if tok.Type != eofToken {
+		return net, p.lexer.errorf(tok, "unexpected %q after net", tok.Text) // Return the error
+	}
+
+	return net, nil // Return the net
+}
+
+// parseNode parses a node, beginning with the node keyword.
+func (p *parser) parseNode() (activation.Node, error) {
+	node := activation.Node{Alive: true} // Nodes are alive by default
+
+	err := p.expect(identToken, "node") // Parse the keyword
+	if err != nil {                     // Check for errors
+		return node, err // Return the error
+	}
+
+	dead, err := p.accept(identToken, "dead") // Check the node is dead
+	if err != nil {                           // Check for errors
+		return node, err // Return the error
+	}
+
+	node.Alive = !dead // Set whether or not the node is alive
+
+	node.Function, err = p.parseComputation() // Parse the node's function
+	if err != nil {                           // Check for errors
+		return node, err // Return the error
+	}
+
+	err = p.expect(symbolToken, "{") // Open the node's body
+	if err != nil {                  // Check for errors
+		return node, err // Return the error
+	}
+
+	// Parse each of the node's links
+	for {
+		// Check the body has been closed
+		if ok, err := p.accept(symbolToken, "}"); err != nil || ok {
+			return node, err // Return the node
+		}
+
+		link, err := p.parseLink() // Parse the link
+		if err != nil {            // Check for errors
+			return node, err // Return the error
+		}
+
+		node.Links = append(node.Links, link) // Add the link
+	}
+}
+
+// parseLink parses a conditional link, beginning with the link keyword.
+func (p *parser) parseLink() (activation.ConditionalLink, error) {
+	link := activation.ConditionalLink{Alive: true} // Links are alive by default
+
+	err := p.expect(identToken, "link") // Parse the keyword
+	if err != nil {                     // Check for errors
+		return link, err // Return the error
+	}
+
+	dead, err := p.accept(identToken, "dead") // Check the link is dead
+	if err != nil {                           // Check for errors
+		return link, err // Return the error
+	}
+
+	link.Alive = !dead // Set whether or not the link is alive
+
+	link.Condition, err = p.parseCondition() // Parse the link's condition
+	if err != nil {                          // Check for errors
+		return link, err // Return the error
+	}
+
+	link.Comparator, err = p.parseParameter() // Parse the link's comparator
+	if err != nil {                           // Check for errors
+		return link, err // Return the error
+	}
+
+	err = p.expect(symbolToken, "->") // Parse the arrow
+	if err != nil {                   // Check for errors
+		return link, err // Return the error
+	}
+
+	// Check the link has no destination
+	if ok, err := p.accept(identToken, "none"); err != nil || ok {
+		return link, err // Return the link
+	}
+
+	link.Destination, err = p.parseNode() // Parse the link's destination
+
+	return link, err // Return the link
+}
+
+// parseCondition parses a link's condition.
+func (p *parser) parseCondition() (activation.Condition, error) {
+	tok, err := p.next() // Get the condition
+	if err != nil {      // Check for errors
+		return 0, err // Return the error
+	}
+
+	// Iterate through the named conditions
+	for condition, name := range conditionNames {
+		// Check the names match
+		if name == tok.Text && tok.Type != stringToken {
+			return condition, nil // Return the condition
+		}
+	}
+
+	// Check the condition is numbered
+	if tok.Type == identToken && strings.HasPrefix(tok.Text, "cond") {
+		n, err := strconv.Atoi(strings.TrimPrefix(tok.Text, "cond")) // Parse the number
+		if err == nil {                                              // Check the number is valid
+			return activation.Condition(n), nil // Return the condition
+		}
+	}
+
+	return 0, p.lexer.errorf(tok, "expected a condition, found %q", tok.Text) // Return the error
+}
+
+// parseComputation parses a computation's operation and parameter.
+func (p *parser) parseComputation() (activation.Computation, error) {
+	var comp activation.Computation // Get a buffer to parse the computation into
+
+	tok, err := p.expectType(identToken, "an operation") // Parse the operation's name
+	if err != nil {                                      // Check for errors
+		return comp, err // Return the error
+	}
+
+	// Check the operation is registered
+	if op, ok := p.operations.LookupName(tok.Text); ok {
+		comp.Type = op // Set the operation
+	} else if n, err := strconv.Atoi(strings.TrimPrefix(tok.Text, "op")); err == nil && strings.HasPrefix(tok.Text, "op") {
+		comp.Type = activation.Operation(n) // Set the numbered operation
+	} else {
+		return comp, p.lexer.errorf(tok, "unknown operation %q", tok.Text) // Return the error
+	}
+
+	comp.Parameter, err = p.parseParameter() // Parse the computation's parameter
+
+	return comp, err // Return the computation
+}
+
+// parseParameter parses a parameter.
+func (p *parser) parseParameter() (activation.Parameter, error) {
+	var param activation.Parameter // Get a buffer to parse the parameter into
+
+	tok, err := p.peek() // Get the first token of the parameter
+	if err != nil {      // Check for errors
+		return param, err // Return the error
+	}
+
+	// Handle the different forms of parameters
+	switch {
+	case tok.Type == intToken:
+		param.I, err = p.parseInt() // Parse the int
+	case tok.Type == hexToken:
+		param.B, err = p.parseBytes() // Parse the bytes
+	case tok.Type == symbolToken && tok.Text == "(":
+		p.peeked = false // Consume the opening parenthesis
+
+		param.I, err = p.parseInt() // Parse the int
+		if err != nil {             // Check for errors
+			return param, err // Return the error
+		}
+
+		param.B, err = p.parseBytes() // Parse the bytes
+		if err != nil {               // Check for errors
+			return param, err // Return the error
+		}
+
+		// Check the tuple has been closed
+		if ok, err := p.accept(symbolToken, ")"); err != nil || ok {
+			return param, err // Return the parameter
+		}
+
+		param.A, err = p.parseAbstract() // Parse the abstract value
+		if err != nil {                  // Check for errors
+			return param, err // Return the error
+		}
+
+		err = p.expect(symbolToken, ")") // Close the tuple
+	default:
+		param.A, err = p.parseAbstract() // Parse the abstract value
+	}
+
+	return param, err // Return the parameter
+}
+
+// parseInt parses an int literal.
+func (p *parser) parseInt() (int, error) {
+	tok, err := p.expectType(intToken, "an int") // Get the literal
+	if err != nil {                              // Check for errors
+		return 0, err // Return the error
+	}
+
+	n, err := strconv.Atoi(tok.Text) // Parse the literal
+	if err != nil {                  // Check for errors
+		return 0, p.lexer.errorf(tok, "invalid int %q", tok.Text) // Return the error
+	}
+
+	return n, nil // Return the int
+}
+
+// parseBytes parses a hex literal.
+func (p *parser) parseBytes() ([]byte, error) {
+	tok, err := p.expectType(hexToken, "bytes") // Get the literal
+	if err != nil {                             // Check for errors
+		return nil, err // Return the error
+	}
+
+	b, err := hex.DecodeString(tok.Text[2:]) // Decode the literal
+	if err != nil {                          // Check for errors
+		return nil, p.lexer.errorf(tok, "invalid bytes %q", tok.Text) // Return the error
+	}
+
+	// Check the literal is empty
+	if len(b) == 0 {
+		return nil, nil // Return no bytes
+	}
+
+	return b, nil // Return the bytes
+}
+
+// parseAbstract parses an abstract value.
+func (p *parser) parseAbstract() (activation.Abstract, error) {
+	var a activation.Abstract // Get a buffer to parse the abstract value into
+
+	tok, err := p.expectType(identToken, "a parameter") // Get the kind of the abstract value
+	if err != nil {                                     // Check for errors
+		return a, err // Return the error
+	}
+
+	err = p.expect(symbolToken, "(") // Open the abstract value
+	if err != nil {                  // Check for errors
+		return a, err // Return the error
+	}
+
+	// Check the abstract value is empty
+	if ok, err := p.accept(symbolToken, ")"); err != nil || ok {
+		// Check for errors
+		if err != nil {
+			return a, err // Return the error
+		}
+
+		// Handle the different kinds
+		switch tok.Text {
+		case "fn":
+			a.Kind = activation.ComputationKind // Set the kind of the value
+		case "ref":
+			a.Kind = activation.NodeKind // Set the kind of the value
+		default:
+			return a, p.lexer.errorf(tok, "expected a parameter, found %q", tok.Text) // Return the error
+		}
+
+		return a, nil // Return the empty value
+	}
+
+	// Handle the different kinds
+	switch tok.Text {
+	case "fn":
+		comp, err := p.parseComputation() // Parse the computation
+		if err != nil {                   // Check for errors
+			return a, err // Return the error
+		}
+
+		a = activation.Abstract{Kind: activation.ComputationKind, Computation: &comp} // Set the computation
+	case "ref":
+		node, err := p.parseNode() // Parse the node
+		if err != nil {            // Check for errors
+			return a, err // Return the error
+		}
+
+		a = activation.Abstract{Kind: activation.NodeKind, Node: &node} // Set the node
+	case "err":
+		msg, err := p.expectType(stringToken, "an error message") // Parse the error's message
+		if err != nil {                                           // Check for errors
+			return a, err // Return the error
+		}
+
+		a = activation.NewErrorParameter(activation.ErrorFromMessage(msg.Text)).A // Set the error
+	default:
+		return a, p.lexer.errorf(tok, "expected a parameter, found %q", tok.Text) // Return the error
+	}
+
+	return a, p.expect(symbolToken, ")") // Close the abstract value
+}
+
+// next consumes the next token.
+func (p *parser) next() (token, error) {
+	// Check a token has already been read
+	if p.peeked {
+		p.peeked = false // Consume the token
+
+		return p.tok, nil // Return the token
+	}
+
+	tok, err := p.lexer.next() // Read the next token
+	if err != nil {            // Check for errors
+		return tok, err // Return the error
+	}
+
+	p.tok = tok // Set the current token
+
+	return tok, nil // Return the token
+}
+
+// peek reads the next token without consuming it.
+func (p *parser) peek() (token, error) {
+	// Check a token has already been read
+	if p.peeked {
+		return p.tok, nil // Return the token
+	}
+
+	tok, err := p.next() // Read the next token
+	if err != nil {      // Check for errors
+		return tok, err // Return the error
+	}
+
+	p.peeked = true // Don't consume the token
+
+	return tok, nil // Return the token
+}
+
+// accept consumes the next token if it is of the given type and text, and
+// reports whether or not it was consumed.
+func (p *parser) accept(t tokenType, text string) (bool, error) {
+	tok, err := p.peek() // Get the next token
+	if err != nil {      // Check for errors
+		return false, err // Return the error
+	}
+
+	// Check the token doesn't match
+	if tok.Type != t || tok.Text != text {
+		return false, nil // Don't consume the token
+	}
+
+	p.peeked = false // Consume the token
+
+	return true, nil // The token was consumed
+}
+
+// expect consumes the next token, which must be of the given type and text.
+func (p *parser) expect(t tokenType, text string) error {
+	tok, err := p.next() // Get the next token
+	if err != nil {      // Check for errors
+		return err // Return the error
+	}
+
+	// Check the token doesn't match
+	if tok.Type != t || tok.Text != text {
+		return p.lexer.errorf(tok, "expected %q, found %s", text, describe(tok)) // Return the error
+	}
+
+	return nil // No error occurred, return nil
+}
+
+// expectType consumes the next token, which must be of the given type. The
+// given description is used in the error if it isn't.
+func (p *parser) expectType(t tokenType, description string) (token, error) {
+	tok, err := p.next() // Get the next token
+	if err != nil {      // Check for errors
+		return tok, err // Return the error
+	}
+
+	// Check the token doesn't match
+	if tok.Type != t {
+		return tok, p.lexer.errorf(tok, "expected %s, found %s", description, describe(tok)) // Return the error
+	}
+
+	return tok, nil // Return the token
+}
+
+// describe gets a description of the given token for use in errors.
+func describe(tok token) string {
+	// Check the token is the end of the source
+	if tok.Type == eofToken {
+		return "end of input" // Describe the end of the source
+	}
+
+	return strconv.Quote(tok.Text) // Describe the token's text
+}
+
+/* END INTERNAL METHODS */
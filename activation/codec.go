@@ -0,0 +1,389 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// maxDecodingDepth is the maximum depth to which nested values (e.g. nodes
+// within links within nodes) will be decoded.
+const maxDecodingDepth = 512
+
+var (
+	// ErrInvalidEncoding is an error definition describing an encoded value
+	// that is truncated or otherwise malformed.
+	ErrInvalidEncoding = errors.New("invalid encoding")
+
+	// ErrEncodingTooDeep is an error definition describing an encoded value
+	// whose nesting exceeds the maximum decoding depth.
+	ErrEncodingTooDeep = errors.New("encoding nested too deeply")
+)
+
+// encoder writes the components of an activation net to a byte slice in a
+// compact binary format. Integers are written as varints, and variable-length
+// values are prefixed with their lengths.
+type encoder struct {
+	buf []byte // the encoded bytes
+}
+
+// decoder reads the components of an activation net from a byte slice
+// written by an encoder. The first error encountered is retained, and all
+// subsequent reads yield zero values.
+type decoder struct {
+	buf []byte // the remaining bytes to decode
+
+	depth int // the current nesting depth
+
+	version byte // the version of the genome format being decoded
+
+	err error // the first error encountered
+}
+
+/* BEGIN INTERNAL METHODS */
+
+// uvarint writes an unsigned varint.
+func (e *encoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte // Get a buffer to write the varint into
+
+	n := binary.PutUvarint(b[:], v) // Write the varint
+
+	e.buf = append(e.buf, b[:n]...) // Append the varint
+}
+
+// varint writes a signed varint.
+func (e *encoder) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte // Get a buffer to write the varint into
+
+	n := binary.PutVarint(b[:], v) // Write the varint
+
+	e.buf = append(e.buf, b[:n]...) // Append the varint
+}
+
+// bool writes a boolean as a single byte.
+func (e *encoder) bool(v bool) {
+	// Check the value is true
+	if v {
+		e.buf = append(e.buf, 1) // Write a one
+
+		return // Done
+	}
+
+	e.buf = append(e.buf, 0) // Write a zero
+}
+
+// bytes writes a length-prefixed byte slice.
+func (e *encoder) bytes(b []byte) {
+	e.uvarint(uint64(len(b)))   // Write the length of the slice
+	e.buf = append(e.buf, b...) // Write the slice
+}
+
+// parameter writes a parameter.
+func (e *encoder) parameter(p Parameter) {
+	e.varint(int64(p.I)) // Write the int value
+	e.bytes(p.B)         // Write the byte value
+	e.abstract(p.A)      // Write the abstract value
+}
+
+// abstract writes an abstract value.
+func (e *encoder) abstract(a Abstract) {
+	e.uvarint(uint64(a.Kind)) // Write the kind of the value
+
+	// Handle the different kinds
+	switch a.Kind {
+	case ComputationKind:
+		e.bool(a.Computation != nil) // Write whether or not the computation exists
+
+		// Check the computation exists
+		if a.Computation != nil {
+			e.computation(*a.Computation) // Write the computation
+		}
+	case NodeKind:
+		e.bool(a.Node != nil) // Write whether or not the node exists
+
+		// Check the node exists
+		if a.Node != nil {
+			e.node(*a.Node) // Write the node by value
+		}
+	case ErrorKind:
+		e.bytes([]byte(errorMessage(a.Error))) // Write the error's message
+	}
+}
+
+// computation writes a computation.
+func (e *encoder) computation(comp Computation) {
+	e.varint(int64(comp.Type))  // Write the type of the computation
+	e.parameter(comp.Parameter) // Write the computation's parameter
+}
+
+// node writes a node.
+func (e *encoder) node(node Node) {
+	e.computation(node.Function)       // Write the node's function
+	e.uvarint(uint64(len(node.Links))) // Write the number of links
+
+	// Iterate through the node's links
+	for _, link := range node.Links {
+		e.link(link) // Write the link
+	}
+
+	e.bool(node.Alive) // Write whether or not the node is alive
+}
+
+// link writes a conditional link.
+func (e *encoder) link(link ConditionalLink) {
+	e.varint(int64(link.Condition)) // Write the link's condition
+	e.parameter(link.Comparator)    // Write the link's comparator
+	e.node(link.Destination)        // Write the link's destination
+	e.bool(link.Alive)              // Write whether or not the link is alive
+}
+
+// net writes a net.
+func (e *encoder) net(net Net) {
+	e.uvarint(uint64(len(net.RootNodes))) // Write the number of root nodes
+
+	// Iterate through the net's root nodes
+	for _, node := range net.RootNodes {
+		e.node(node) // Write the root node
+	}
+
+	e.varint(int64(net.Reducer))  // Write the net's reducer
+	e.varint(int64(net.LinkMode)) // Write the net's link mode
+	e.varint(int64(net.Combiner)) // Write the net's combiner
+}
+
+// fail records the given error, if no error has been recorded yet.
+func (d *decoder) fail(err error) {
+	// Check no error has been recorded
+	if d.err == nil {
+		d.err = err // Record the error
+	}
+
+	d.buf = nil // Stop reading
+}
+
+// enter increments the nesting depth, and reports whether or not decoding
+// may continue.
+func (d *decoder) enter() bool {
+	d.depth++ // Increment the depth
+
+	// Check the depth is exceeded
+	if d.depth > maxDecodingDepth {
+		d.fail(ErrEncodingTooDeep) // Record the error
+	}
+
+	return d.err == nil // Return whether or not decoding may continue
+}
+
+// leave decrements the nesting depth.
+func (d *decoder) leave() {
+	d.depth-- // Decrement the depth
+}
+
+// finish checks that the decoder consumed its entire buffer without errors.
+func (d *decoder) finish() error {
+	// Check for errors
+	if d.err != nil {
+		return d.err // Return the error
+	}
+
+	// Check there are remaining bytes
+	if len(d.buf) > 0 {
+		return ErrInvalidEncoding // Return the error
+	}
+
+	return nil // No error occurred, return nil
+}
+
+// uvarint reads an unsigned varint.
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf) // Read the varint
+	if n <= 0 {                   // Check for errors
+		d.fail(ErrInvalidEncoding) // Record the error
+
+		return 0 // Return a zero value
+	}
+
+	d.buf = d.buf[n:] // Advance the buffer
+
+	return v // Return the value
+}
+
+// varint reads a signed varint.
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.buf) // Read the varint
+	if n <= 0 {                  // Check for errors
+		d.fail(ErrInvalidEncoding) // Record the error
+
+		return 0 // Return a zero value
+	}
+
+	d.buf = d.buf[n:] // Advance the buffer
+
+	return v // Return the value
+}
+
+// bool reads a boolean written as a single byte.
+func (d *decoder) bool() bool {
+	// Check the buffer is empty or the byte isn't a boolean
+	if len(d.buf) == 0 || d.buf[0] > 1 {
+		d.fail(ErrInvalidEncoding) // Record the error
+
+		return false // Return a zero value
+	}
+
+	v := d.buf[0] == 1 // Read the boolean
+
+	d.buf = d.buf[1:] // Advance the buffer
+
+	return v // Return the value
+}
+
+// count reads a length or count prefix, checking that it doesn't exceed the
+// number of remaining bytes (every element takes at least one byte).
+func (d *decoder) count() int {
+	n := d.uvarint() // Read the count
+
+	// Check the count exceeds the remaining bytes
+	if n > uint64(len(d.buf)) {
+		d.fail(ErrInvalidEncoding) // Record the error
+
+		return 0 // Return a zero value
+	}
+
+	return int(n) // Return the count
+}
+
+// bytes reads a length-prefixed byte slice.
+func (d *decoder) bytes() []byte {
+	n := d.count() // Read the length of the slice
+
+	// Check the slice is empty
+	if n == 0 {
+		return nil // Return an empty slice
+	}
+
+	b := append([]byte(nil), d.buf[:n]...) // Copy the slice
+
+	d.buf = d.buf[n:] // Advance the buffer
+
+	return b // Return the slice
+}
+
+// parameter reads a parameter.
+func (d *decoder) parameter() Parameter {
+	// Check decoding may continue
+	if !d.enter() {
+		return Parameter{} // Return a zero value
+	}
+	defer d.leave() // Leave the parameter once done
+
+	return Parameter{
+		I: int(d.varint()), // Read the int value
+		B: d.bytes(),       // Read the byte value
+		A: d.abstract(),    // Read the abstract value
+	} // Return the parameter
+}
+
+// abstract reads an abstract value.
+func (d *decoder) abstract() Abstract {
+	a := Abstract{Kind: Kind(d.uvarint())} // Read the kind of the value
+
+	// Handle the different kinds
+	switch a.Kind {
+	case NoneKind:
+	case ComputationKind:
+		// Check the computation exists
+		if d.bool() {
+			comp := d.computation() // Read the computation
+
+			a.Computation = &comp // Set the computation
+		}
+	case NodeKind:
+		// Check the node exists
+		if d.bool() {
+			node := d.node() // Read the node
+
+			a.Node = &node // Set the node
+		}
+	case ErrorKind:
+		a.Error = ErrorFromMessage(string(d.bytes())) // Read the error
+	default:
+		d.fail(ErrUnknownKind) // Abstract values can't hold ints or bytes
+	}
+
+	return a // Return the value
+}
+
+// computation reads a computation.
+func (d *decoder) computation() Computation {
+	return Computation{
+		Type:      Operation(d.varint()), // Read the type of the computation
+		Parameter: d.parameter(),         // Read the computation's parameter
+	} // Return the computation
+}
+
+// node reads a node.
+func (d *decoder) node() Node {
+	// Check decoding may continue
+	if !d.enter() {
+		return Node{} // Return a zero value
+	}
+	defer d.leave() // Leave the node once done
+
+	node := Node{Function: d.computation()} // Read the node's function
+
+	n := d.count() // Read the number of links
+
+	// Check the node has links
+	if n > 0 {
+		node.Links = make([]ConditionalLink, n) // Make a buffer for the links
+	}
+
+	// Read each of the links
+	for i := range node.Links {
+		node.Links[i] = d.link() // Read the link
+	}
+
+	node.Alive = d.bool() // Read whether or not the node is alive
+
+	return node // Return the node
+}
+
+// link reads a conditional link.
+func (d *decoder) link() ConditionalLink {
+	return ConditionalLink{
+		Condition:   Condition(d.varint()), // Read the link's condition
+		Comparator:  d.parameter(),         // Read the link's comparator
+		Destination: d.node(),              // Read the link's destination
+		Alive:       d.bool(),              // Read whether or not the link is alive
+	} // Return the link
+}
+
+// net reads a net.
+func (d *decoder) net() Net {
+	var net Net // Get a buffer to read the net into
+
+	n := d.count() // Read the number of root nodes
+
+	// Check the net has root nodes
+	if n > 0 {
+		net.RootNodes = make([]Node, n) // Make a buffer for the root nodes
+	}
+
+	// Read each of the root nodes
+	for i := range net.RootNodes {
+		net.RootNodes[i] = d.node() // Read the root node
+	}
+
+	net.Reducer = Reducer(d.varint()) // Read the net's reducer
+
+	// Check the net was encoded with a link mode and combiner
+	if d.version >= 2 {
+		net.LinkMode = LinkMode(d.varint()) // Read the net's link mode
+		net.Combiner = Reducer(d.varint())  // Read the net's combiner
+	}
+
+	return net // Return the net
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,254 @@
+package activation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// numRoundTripNets is the number of random nets encoded and decoded by the
+// round-trip tests.
+const numRoundTripNets = 200
+
+// TestNetRoundTrip checks that decoding an encoded net yields the same net.
+func TestNetRoundTrip(t *testing.T) {
+	leaf := NewNode(NewComputation(Multiply, Parameter{I: -3}), nil) // Make a node without links
+	branch := NewNode(NewComputation(Add, Parameter{B: []byte{1, 2}}), []ConditionalLink{
+		NewConditionalLink(GreaterThan, Parameter{I: 7}, leaf), // Follow the link to the leaf if the output is large enough
+		NewConditionalLink(Unconditional, Parameter{}, Node{}), // Follow the link to nothing otherwise
+	}) // Make a node with links
+
+	fanned := NewNet([]Node{branch, leaf}) // Make a net that fans out
+	fanned.Reducer = ConcatReducer         // Concatenate the outputs of the root nodes
+	fanned.LinkMode = ParallelFanOut       // Follow each of the satisfied links
+	fanned.Combiner = MaxReducer           // Take the largest output of the branches
+
+	tests := []struct {
+		name string // the name of the test
+		net  Net    // the net to encode
+	}{
+		{"empty", Net{}},
+		{"leaf", NewNet([]Node{leaf})},
+		{"fanned out", fanned},
+		{"dead", NewNet([]Node{{Function: leaf.Function, Links: branch.Links, Alive: false}})},
+	}
+
+	// Iterate through the seeds of the random nets to encode
+	for seed := int64(0); seed < numRoundTripNets; seed++ {
+		tests = append(tests, struct {
+			name string
+			net  Net
+		}{"random", RandomNet(NewEnvironment(seed))}) // Add the random net
+	}
+
+	// Iterate through the tests
+	for i, test := range tests {
+		encoded := EncodeNet(test.net) // Encode the net
+
+		decoded, err := DecodeNet(encoded) // Decode the net
+		if err != nil {                    // Check for errors
+			t.Fatalf("%s (%d): %v", test.name, i, err) // Fail
+		}
+
+		// Check the decoded net differs
+		if !decoded.DeepEquals(&test.net) || !bytes.Equal(EncodeNet(decoded), encoded) {
+			t.Fatalf("%s (%d): decoded %+v, encoded %+v", test.name, i, decoded, test.net) // Fail
+		}
+
+		// Check the configuration of the net was lost
+		if decoded.Reducer != test.net.Reducer || decoded.LinkMode != test.net.LinkMode || decoded.Combiner != test.net.Combiner {
+			t.Fatalf("%s (%d): decoded configuration %v/%v/%v, encoded %v/%v/%v", test.name, i, decoded.Reducer, decoded.LinkMode, decoded.Combiner, test.net.Reducer, test.net.LinkMode, test.net.Combiner) // Fail
+		}
+	}
+}
+
+// TestParameterRoundTrip checks that decoding an encoded parameter yields the
+// same parameter, for each of the kinds of values a parameter can hold.
+func TestParameterRoundTrip(t *testing.T) {
+	node := NewNode(NewComputation(Subtract, Parameter{I: 1}), []ConditionalLink{NewConditionalLink(EqualTo, Parameter{I: 2}, Node{})}) // Make a node to reference
+
+	tests := []struct {
+		name  string    // the name of the test
+		param Parameter // the parameter to encode
+	}{
+		{"zero", Parameter{}},
+		{"int", Parameter{I: -42}},
+		{"bytes", Parameter{B: []byte("eve")}},
+		{"computation", NewComputationParameter(NewComputation(Divide, Parameter{I: 2}))},
+		{"nil computation", Parameter{A: Abstract{Kind: ComputationKind}}},
+		{"node", NewNodeParameter(&node)},
+		{"nil node", Parameter{A: Abstract{Kind: NodeKind}}},
+		{"known error", NewErrorParameter(ErrOutOfGas)},
+		{"unknown error", NewErrorParameter(errors.New("unknown"))},
+		{"mixed", Parameter{I: 3, B: []byte{0xff}, A: NewComputationParameter(NewComputation(Add, Parameter{I: 1})).A}},
+	}
+
+	// Iterate through the tests
+	for _, test := range tests {
+		encoded := EncodeParameter(test.param) // Encode the parameter
+
+		decoded, err := DecodeParameter(encoded) // Decode the parameter
+		if err != nil {                          // Check for errors
+			t.Fatalf("%s: %v", test.name, err) // Fail
+		}
+
+		// Check the decoded parameter differs
+		if !decoded.DeepEquals(&test.param) || !bytes.Equal(EncodeParameter(decoded), encoded) {
+			t.Fatalf("%s: decoded %+v, encoded %+v", test.name, decoded, test.param) // Fail
+		}
+	}
+
+	decoded, err := DecodeParameter(EncodeParameter(NewErrorParameter(ErrOutOfGas))) // Decode a known error
+	if err != nil {                                                                  // Check for errors
+		t.Fatal(err) // Fail
+	}
+
+	// Check the known error wasn't preserved by identity
+	if decoded.A.Error != ErrOutOfGas {
+		t.Fatalf("decoded error %v isn't ErrOutOfGas", decoded.A.Error) // Fail
+	}
+}
+
+// TestDecodeVersion1 checks that genomes written in version 1 of the format,
+// which didn't hold the link modes and combiners of nets, can still be
+// decoded.
+func TestDecodeVersion1(t *testing.T) {
+	// Iterate through the seeds of the random nets to encode
+	for seed := int64(0); seed < numRoundTripNets; seed++ {
+		net := RandomNet(NewEnvironment(seed)) // Generate a random net
+		net.Reducer = Reducer(seed % 5)        // Use each of the reducers
+
+		var payload encoder // Get an encoder for the version 1 net
+
+		payload.uvarint(uint64(len(net.RootNodes))) // Write the number of root nodes
+
+		// Iterate through the net's root nodes
+		for _, node := range net.RootNodes {
+			payload.node(node) // Write the root node
+		}
+
+		payload.varint(int64(net.Reducer)) // Write the net's reducer
+
+		decoded, err := DecodeNet(frameGenome(1, netGenome, payload.buf)) // Decode the version 1 net
+		if err != nil {                                                   // Check for errors
+			t.Fatalf("seed %d: %v", seed, err) // Fail
+		}
+
+		// Check the decoded net differs
+		if !decoded.DeepEquals(&net) {
+			t.Fatalf("seed %d: decoded %+v, encoded %+v", seed, decoded, net) // Fail
+		}
+
+		// Check the net wasn't given the default link mode and combiner
+		if decoded.LinkMode != FirstMatch || decoded.Combiner != LastReducer {
+			t.Fatalf("seed %d: version 1 net decoded with link mode %v, combiner %v", seed, decoded.LinkMode, decoded.Combiner) // Fail
+		}
+	}
+
+	var payload encoder // Get an encoder for a version 1 parameter
+
+	param := NewComputationParameter(NewComputation(Add, Parameter{I: 1, B: []byte{2}})) // Get a parameter to encode
+
+	payload.parameter(param) // Write the parameter, which is the same in both versions
+
+	decoded, err := DecodeParameter(frameGenome(1, parameterGenome, payload.buf)) // Decode the version 1 parameter
+	if err != nil {                                                               // Check for errors
+		t.Fatal(err) // Fail
+	}
+
+	// Check the decoded parameter differs
+	if !decoded.DeepEquals(&param) {
+		t.Fatalf("decoded %+v, encoded %+v", decoded, param) // Fail
+	}
+}
+
+// TestDecodeRejects checks that malformed genomes are rejected with the
+// corresponding errors.
+func TestDecodeRejects(t *testing.T) {
+	net := RandomNet(NewEnvironment(1)) // Generate a net to corrupt
+
+	var payload encoder // Get an encoder for the net
+
+	payload.net(net) // Write the net
+
+	valid := frameGenome(GenomeVersion, netGenome, payload.buf) // Frame the net
+
+	var deep encoder // Get an encoder for a net nested too deeply
+
+	nested := Parameter{I: 1} // Start with a plain parameter
+
+	// Nest the parameter deeper than can be decoded
+	for i := 0; i < maxDecodingDepth; i++ {
+		nested = NewComputationParameter(NewComputation(Add, nested)) // Nest the parameter
+	}
+
+	deep.net(NewNet([]Node{NewNode(NewComputation(Add, nested), nil)})) // Write the net
+
+	tests := []struct {
+		name   string // the name of the test
+		genome []byte // the genome to decode
+		err    error  // the expected error
+	}{
+		{"empty", nil, ErrNotAGenome},
+		{"bad magic", append([]byte("EVA"), valid[len(genomeMagic):]...), ErrNotAGenome},
+		{"magic only", genomeMagic, ErrInvalidEncoding},
+		{"version 0", frameGenome(0, netGenome, payload.buf), ErrUnsupportedGenomeVersion},
+		{"future version", frameGenome(GenomeVersion+1, netGenome, payload.buf), ErrUnsupportedGenomeVersion},
+		{"wrong type", frameGenome(GenomeVersion, nodeGenome, payload.buf), ErrGenomeTypeMismatch},
+		{"bad checksum", flip(valid, len(valid)-1), ErrChecksumMismatch},
+		{"corrupted value", flip(valid, len(valid)-5), ErrChecksumMismatch},
+		{"truncated genome", valid[:len(valid)-1], ErrChecksumMismatch},
+		{"length exceeds value", sealGenome(frame(GenomeVersion, netGenome, uint64(len(payload.buf)+1), payload.buf)), ErrInvalidEncoding},
+		{"length short of value", sealGenome(frame(GenomeVersion, netGenome, uint64(len(payload.buf)-1), payload.buf)), ErrInvalidEncoding},
+		{"truncated value", frameGenome(GenomeVersion, netGenome, payload.buf[:len(payload.buf)-1]), ErrInvalidEncoding},
+		{"trailing value bytes", frameGenome(GenomeVersion, netGenome, append(append([]byte(nil), payload.buf...), 0)), ErrInvalidEncoding},
+		{"too deep", frameGenome(GenomeVersion, netGenome, deep.buf), ErrEncodingTooDeep},
+	}
+
+	// Iterate through the tests
+	for _, test := range tests {
+		_, err := DecodeNet(test.genome) // Decode the genome
+
+		// Check the genome wasn't rejected with the expected error
+		if err != test.err {
+			t.Fatalf("%s: got error %v, want %v", test.name, err, test.err) // Fail
+		}
+	}
+}
+
+// frameGenome wraps the given encoded value in a genome of the given version
+// and type.
+func frameGenome(version byte, t genomeType, value []byte) []byte {
+	return sealGenome(frame(version, t, uint64(len(value)), value)) // Return the genome
+}
+
+// frame writes the body of a genome of the given version and type, prefixing
+// the given value with the given length.
+func frame(version byte, t genomeType, length uint64, value []byte) []byte {
+	e := encoder{buf: append([]byte(nil), genomeMagic...)} // Start the genome with the magic bytes
+
+	e.buf = append(e.buf, version, byte(t)) // Write the version and type
+	e.uvarint(length)                       // Write the length of the value
+
+	return append(e.buf, value...) // Return the body
+}
+
+// sealGenome appends the checksum of the given genome body.
+func sealGenome(body []byte) []byte {
+	var checksum [4]byte // Get a buffer to write the checksum into
+
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(body)) // Write the checksum
+
+	return append(body, checksum[:]...) // Return the genome
+}
+
+// flip copies the given genome, and inverts the byte at the given index.
+func flip(genome []byte, i int) []byte {
+	flipped := append([]byte(nil), genome...) // Copy the genome
+
+	flipped[i] ^= 0xff // Invert the byte
+
+	return flipped // Return the copy
+}
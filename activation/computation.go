@@ -1,10 +1,7 @@
 // Package activation implements a simple activation net.
 package activation
 
-import (
-	"errors"
-	"math/rand"
-)
+import "errors"
 
 var (
 	// ErrComputationHalted is an error definition describing a halting of
@@ -14,9 +11,14 @@ var (
 	// ErrIdentityUnknown is an error definition describing a lack of knowledge
 	// of the outer node's identity.
 	ErrIdentityUnknown = errors.New("identity unknown")
+
+	// ErrOutOfGas is an error definition describing an evaluation that was
+	// cut off after exceeding its gas limit.
+	ErrOutOfGas = errors.New("out of gas")
 )
 
-// Operation represents a type of computation being executed.
+// Operation represents a type of computation being executed. Operations are
+// defined by an OperationRegistry; the built-in operations are listed below.
 type Operation int
 
 const (
@@ -70,10 +72,10 @@ func NewComputation(computationType Operation, param Parameter) Computation {
 	} // Return the new computation
 }
 
-// RandomComputation initializes a new random computation with the given
-// initialization options.
-func RandomComputation(opts ...ComputationInitializationOption) Computation {
-	comp := NewComputation(Operation(rand.Intn(5)), RandomParameter()) // Initialize a random computation
+// RandomComputation initializes a new random computation from the given
+// environment with the given initialization options.
+func RandomComputation(env *Environment, opts ...ComputationInitializationOption) Computation {
+	comp := NewComputation(env.Operations.Random(env.Rand), RandomParameter(env)) // Initialize a random computation
 
 	// Iterate through the provided options
 	for _, opt := range opts {
@@ -85,44 +87,52 @@ func RandomComputation(opts ...ComputationInitializationOption) Computation {
 
 // IsZero checks whether or not the computation has been initialized.
 func (comp *Computation) IsZero() bool {
-	return (comp.Type > 5 || comp.Type < 0) || comp.Parameter.IsZero() // Return whether or not the computation has not been initialized
+	return comp.Type < 0 || comp.Parameter.IsZero() // Return whether or not the computation has not been initialized
 }
 
-// Execute executes a computation with the given parameter. This parameter is
-// the applicant to the computation (e.g. 4 in 4 + 2).
-func (comp *Computation) Execute(param Parameter) Parameter {
-	// Handle different computation types
-	switch comp.Type {
-	case Add:
-		return param.Add(&comp.Parameter) // Return the added parameter
-	case Subtract:
-		return param.Sub(&comp.Parameter) // Return the subtracted parameter
-	case Multiply:
-		return param.Mul(&comp.Parameter) // Return the multiplied parameter
-	case Divide:
-		return param.Div(&comp.Parameter) // Return the divided parameter
-	case Identity:
-		return NewErrorParameter(ErrIdentityUnknown) // Return an identity error
-	case Inject:
-		// Check parameter has abstract field
-		if comp.Parameter.A != nil && param.A != nil {
-			function, ok := comp.Parameter.A.(Computation) // Get the computation to inject into the node
-			if !ok {                                       // Check could not cast
-				return NewErrorParameter(ErrComputationHalted) // Return an err parameter
-			}
-
-			destination, ok := param.A.(*Node) // Get the node to set the function of
-			if !ok {                           // Check could not cast
-				return NewErrorParameter(ErrComputationHalted) // Return an err parameter
-			}
-
-			destination.Function = function // Set the function of the node
-		}
-
-		return comp.Parameter
-	default:
+// Execute executes a computation with the given parameter, using the
+// operations registered in the given environment. This parameter is the
+// applicant to the computation (e.g. 4 in 4 + 2). Computations of unknown or
+// disabled operations return their parameter.
+func (comp *Computation) Execute(env *Environment, param Parameter) Parameter {
+	return comp.execute(env, param, make([]Parameter, 2)) // Execute the computation
+}
+
+// Clone makes a deep copy of the computation.
+func (comp *Computation) Clone() Computation {
+	return Computation{
+		Type:      comp.Type,              // Copy the type of the computation
+		Parameter: comp.Parameter.Clone(), // Copy the computation's parameter
+	} // Return the copied computation
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// execute executes a computation with the given parameter, passing the
+// operation its arguments through the given buffer, which must have room for
+// two arguments. Since operations are called indirectly, their arguments
+// would otherwise be allocated on every execution. See Execute.
+func (comp *Computation) execute(env *Environment, param Parameter, args []Parameter) Parameter {
+	def, ok := env.Operations.Lookup(comp.Type) // Get the computation's operation
+
+	// Check the operation can't be executed
+	if !ok || !def.Enabled {
 		return comp.Parameter // Return the initial parameter
 	}
+
+	args[0], args[1] = param, comp.Parameter // Fill the arguments buffer
+
+	// Handle the different arities
+	switch def.Arity {
+	case 0:
+		return def.Execute() // Return the result of the nullary operation
+	case 1:
+		return def.Execute(args[:1]...) // Return the result of the unary operation
+	default:
+		return def.Execute(args[:2]...) // Return the result of the binary operation
+	}
 }
 
-/* END EXPORTED METHODS */
+/* END INTERNAL METHODS */
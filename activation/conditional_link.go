@@ -3,7 +3,6 @@ package activation
 
 import (
 	"math"
-	"math/rand"
 
 	"github.com/dowlandaiello/eve/common"
 )
@@ -63,9 +62,10 @@ func NewConditionalLink(condition Condition, comparator Parameter, destination N
 	} // Return the initialized link
 }
 
-// RandomConditionalLinks initializes a slice of random conditional links.
-func RandomConditionalLinks(opts ...[]ConditionalLinkInitializationOption) []ConditionalLink {
-	n := rand.Intn(int(math.Pow(4, float64(rand.Intn(common.ComputationalDifficulty))))) // Get a random number of links to initialize
+// RandomConditionalLinks initializes a slice of random conditional links from
+// the given environment.
+func RandomConditionalLinks(env *Environment, opts ...[]ConditionalLinkInitializationOption) []ConditionalLink {
+	n := env.Rand.Intn(int(math.Pow(4, float64(env.Rand.Intn(common.ComputationalDifficulty))))) // Get a random number of links to initialize
 
 	var links []ConditionalLink // Declare a buffer to store the initialized links in
 
@@ -73,32 +73,32 @@ func RandomConditionalLinks(opts ...[]ConditionalLinkInitializationOption) []Con
 	for i := 0; i < n; i++ {
 		// Check options for the link exist
 		if len(opts) > n {
-			links = append(links, RandomConditionalLink(opts[i]...)) // Add the conditional link to the stack of links
+			links = append(links, RandomConditionalLink(env, opts[i]...)) // Add the conditional link to the stack of links
 
 			continue // Continue
 		}
 
-		links = append(links, RandomConditionalLink()) // Add the conditional link to the stack of links
+		links = append(links, RandomConditionalLink(env)) // Add the conditional link to the stack of links
 	}
 
 	return links // Return the generated links
 }
 
-// RandomConditionalLink initializes a new random conditional link with the
-// given initialization options.
-func RandomConditionalLink(opts ...ConditionalLinkInitializationOption) ConditionalLink {
+// RandomConditionalLink initializes a new random conditional link from the
+// given environment with the given initialization options.
+func RandomConditionalLink(env *Environment, opts ...ConditionalLinkInitializationOption) ConditionalLink {
 	var destination Node // Declare a buffer to store a potential destination in
 
 	// Generate a destination node based on a 50/50 coin flip
-	if rand.Intn(2) == 0 {
-		destination = RandomNode() // Set the destination to a random node
+	if env.Rand.Intn(2) == 0 {
+		destination = RandomNode(env) // Set the destination to a random node
 	}
 
 	link := ConditionalLink{
-		Condition:   Condition(rand.Intn(7)), // Set the condition of the link to a random condition
-		Comparator:  RandomParameter(),       // Set the comparator of the link to a random parameter
-		Destination: destination,             // Set the destination to the conditionally generated destination node (exists only if 50/50 coin flip lands on heads)
-		Alive:       true,                    // All nodes are alive by default
+		Condition:   Condition(env.Rand.Intn(7)), // Set the condition of the link to a random condition
+		Comparator:  RandomParameter(env),        // Set the comparator of the link to a random parameter
+		Destination: destination,                 // Set the destination to the conditionally generated destination node (exists only if 50/50 coin flip lands on heads)
+		Alive:       true,                        // All nodes are alive by default
 	} // Initialize a random link
 
 	// Iterate through the provided options
@@ -150,4 +150,14 @@ func (link *ConditionalLink) HasDestination() bool {
 	return !link.Destination.IsZero() // Return whether or not the destination exists
 }
 
+// Clone makes a deep copy of the conditional link.
+func (link *ConditionalLink) Clone() ConditionalLink {
+	return ConditionalLink{
+		Condition:   link.Condition,           // Copy the condition
+		Comparator:  link.Comparator.Clone(),  // Copy the comparator
+		Destination: link.Destination.Clone(), // Copy the destination
+		Alive:       link.Alive,               // Copy whether or not the link is alive
+	} // Return the copied link
+}
+
 /* END EXPORTED METHODS */
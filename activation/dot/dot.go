@@ -0,0 +1,159 @@
+// Package dot implements a Graphviz DOT exporter for activation nets.
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/activation/asm"
+)
+
+// maxLabelLength is the number of characters past which labels are
+// truncated.
+const maxLabelLength = 64
+
+// deadAttributes are the attributes given to dead nodes and links.
+const deadAttributes = `color="grey", fontcolor="grey", style="dashed"`
+
+// Exporter exports activation nets to the DOT language, naming operations
+// according to its operation registry.
+type Exporter struct {
+	Operations *activation.OperationRegistry // the operations used to name computations
+}
+
+// graph holds the state of a single export.
+type graph struct {
+	assembler asm.Assembler // the assembler used to format labels
+
+	buf bytes.Buffer // the exported graph
+
+	n int // the number of graph nodes written
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewExporter initializes a new exporter with the given operations.
+func NewExporter(operations *activation.OperationRegistry) Exporter {
+	return Exporter{
+		Operations: operations, // Set the exporter's operations
+	} // Return the initialized exporter
+}
+
+// Export exports the given net, using the default operations.
+func Export(net activation.Net) string {
+	return NewExporter(activation.DefaultOperations).Export(net) // Export the net
+}
+
+// Export exports the given net as a DOT digraph. Nodes are labeled with their
+// operations and parameters, and edges with their conditions and
+// comparators. Dead nodes and links are greyed out.
+func (exporter Exporter) Export(net activation.Net) string {
+	e := graph{assembler: asm.NewAssembler(exporter.Operations)} // Initialize the export's state
+
+	e.buf.WriteString("digraph net {\n")                                 // Open the graph
+	e.buf.WriteString("  node [shape=box, fontname=\"monospace\"];\n")   // Set the default node attributes
+	e.buf.WriteString("  edge [fontname=\"monospace\", fontsize=10];\n") // Set the default edge attributes
+	label := "reducer: " + net.Reducer.String()                          // Label the graph with its reducer
+
+	// Check the net's nodes fan out
+	if net.LinkMode.IsFanOut() {
+		label += ", links: " + net.LinkMode.String() + ", combiner: " + net.Combiner.String() // Label the graph with its link mode and combiner
+	}
+
+	fmt.Fprintf(&e.buf, "  label=%s;\n", quote(label)) // Label the graph
+
+	// Iterate through the net's root nodes
+	for i, node := range net.RootNodes {
+		e.node(node, fmt.Sprintf("root %d\n", i)) // Export the root node
+	}
+
+	e.buf.WriteString("}\n") // Close the graph
+
+	return e.buf.String() // Return the exported graph
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// node writes the given node, its links, and each of its links'
+// destinations, prefixing the node's label with the given prefix. Returns
+// the identifier of the written node.
+func (e *graph) node(node activation.Node, prefix string) string {
+	id := e.id() // Get an identifier for the node
+
+	attributes := "" // Get a buffer for the node's extra attributes
+
+	// Check the node is dead
+	if !node.Alive {
+		attributes = ", " + deadAttributes // Grey out the node
+	}
+
+	fmt.Fprintf(&e.buf, "  %s [label=%s%s];\n", id, quote(prefix+e.assembler.FormatComputation(node.Function)), attributes) // Write the node
+
+	// Iterate through the node's links
+	for _, link := range node.Links {
+		var destination string // Declare a buffer to store the identifier of the link's destination in
+
+		// Check the link has no destination
+		if link.Destination.IsNone() {
+			destination = e.id() // Get an identifier for the missing destination
+
+			fmt.Fprintf(&e.buf, "  %s [shape=point, %s];\n", destination, deadAttributes) // Write a placeholder
+		} else {
+			destination = e.node(link.Destination, "") // Write the destination
+		}
+
+		attributes := "" // Get a buffer for the edge's extra attributes
+
+		// Check the link is dead
+		if !link.Alive {
+			attributes = ", " + deadAttributes // Grey out the link
+		}
+
+		label := asm.FormatCondition(link.Condition) + " " + e.assembler.FormatParameter(link.Comparator) // Get the edge's label
+
+		fmt.Fprintf(&e.buf, "  %s -> %s [label=%s%s];\n", id, destination, quote(label), attributes) // Write the edge
+	}
+
+	return id // Return the node's identifier
+}
+
+// id gets the identifier of the next graph node.
+func (e *graph) id() string {
+	e.n++ // Count the node
+
+	return fmt.Sprintf("n%d", e.n-1) // Return the node's identifier
+}
+
+// quote quotes the given label as a DOT string, collapsing whitespace and
+// truncating long labels.
+func quote(label string) string {
+	lines := strings.Split(label, "\n") // Split the label into lines, which are preserved
+
+	// Iterate through the lines
+	for i, line := range lines {
+		line = strings.Join(strings.Fields(line), " ") // Collapse the line's whitespace
+
+		// Check the line is too long
+		if len(line) > maxLabelLength {
+			line = line[:maxLabelLength-3] + "..." // Truncate the line
+		}
+
+		line = strings.Replace(line, `\`, `\\`, -1) // Escape backslashes
+		line = strings.Replace(line, `"`, `\"`, -1) // Escape quotes
+
+		lines[i] = line // Set the line
+	}
+
+	// Check the last line is empty
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1] // Remove the trailing line
+	}
+
+	return `"` + strings.Join(lines, `\n`) + `"` // Return the quoted label
+}
+
+/* END INTERNAL METHODS */
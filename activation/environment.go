@@ -0,0 +1,129 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/dowlandaiello/eve/common"
+)
+
+const (
+	// Unlimited is a gas limit that never runs out.
+	Unlimited = -1
+
+	// DefaultLinkMortality is the default probability that a link is killed
+	// as it fires.
+	DefaultLinkMortality = 0.1
+)
+
+// Environment is the set of per-simulation inputs consulted while generating
+// and evaluating activation nets.
+type Environment struct {
+	Rand *rand.Rand // the source of randomness used by the environment
+
+	Operations *OperationRegistry // the operations that can be generated and executed in the environment
+
+	Gas Gas // the budget of steps that evaluations in the environment may take
+
+	LinkMortality float64 // the probability that a link is killed as it fires
+
+	Tracer *Tracer // the tracer recording each step taken by evaluations in the environment (nil if tracing is disabled)
+
+	linkMode LinkMode // the way in which nodes evaluated in the environment follow their links
+	combiner Reducer  // the strategy used to merge the outputs of branches followed by fanned out nodes
+
+	traceRoot int   // the index of the root node being evaluated in the environment
+	tracePath []int // the indices of the links followed from the root node to the node being evaluated
+}
+
+// Gas is a budget of steps that an evaluation may take. Each node executed
+// during an evaluation takes a single step.
+type Gas struct {
+	Limit int // the maximum number of steps that may be taken (Unlimited if negative)
+
+	Used int // the number of steps taken
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewEnvironment initializes a new environment whose source of randomness is
+// seeded with the given seed. The environment uses the default operations,
+// the default gas limit, and the default link mortality.
+func NewEnvironment(seed int64) *Environment {
+	return &Environment{
+		Rand:          rand.New(rand.NewSource(seed)), // Set the environment's source of randomness
+		Operations:    DefaultOperations,              // Use the default operations
+		Gas:           Gas{Limit: DefaultGasLimit()},  // Use the default gas limit
+		LinkMortality: DefaultLinkMortality,           // Use the default link mortality
+	} // Return the initialized environment
+}
+
+// DefaultGasLimit gets the number of steps that a single evaluation may take,
+// given the current computational difficulty.
+func DefaultGasLimit() int {
+	return int(math.Pow(16, float64(common.ComputationalDifficulty))) // Return the gas limit
+}
+
+// Fork derives a new environment from the current environment. The derived
+// environment's source of randomness is seeded from the current environment,
+// and is therefore deterministic, given the current environment's seed. Since
+// a rand.Rand is not safe for concurrent use, concurrent evaluations must each
+// be given their own fork. The fork is given the parent's remaining gas.
+func (env *Environment) Fork() *Environment {
+	forked := *env // Copy the environment
+
+	forked.Rand = rand.New(rand.NewSource(env.Rand.Int63())) // Seed the fork from the parent
+	forked.Gas = Gas{Limit: env.Gas.Remaining()}             // Give the fork the remaining gas
+
+	return &forked // Return the forked environment
+}
+
+// Consume takes a single step, and reports whether or not the step could be
+// taken without exceeding the gas limit.
+func (gas *Gas) Consume() bool {
+	// Check the gas has run out
+	if gas.Limit >= 0 && gas.Used >= gas.Limit {
+		return false // The step can't be taken
+	}
+
+	gas.Used++ // Take the step
+
+	return true // The step was taken
+}
+
+// Remaining gets the number of steps that may still be taken.
+func (gas *Gas) Remaining() int {
+	// Check the gas is unlimited
+	if gas.Limit < 0 {
+		return Unlimited // There's no limit on the remaining steps
+	}
+
+	// Check the gas has run out
+	if gas.Used >= gas.Limit {
+		return 0 // No steps remain
+	}
+
+	return gas.Limit - gas.Used // Return the remaining steps
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// combine merges the outputs of the branches followed by a fanned out node
+// with the environment's combiner. If any of the branches ran out of gas, the
+// evaluation is cut off.
+func (env *Environment) combine(outputs []Parameter) Parameter {
+	// Iterate through the outputs
+	for _, output := range outputs {
+		// Check the branch ran out of gas
+		if output.IsError() && output.A.Error == ErrOutOfGas {
+			return output // Cut off the evaluation
+		}
+	}
+
+	return env.combiner.Reduce(outputs) // Return the merged outputs
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,96 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"bytes"
+	"hash/fnv"
+)
+
+/* BEGIN EXPORTED METHODS */
+
+// DeepEquals checks whether or not two parameters hold identical values.
+// Unlike Equals, which decides the conditions of links, each of the int,
+// byte, and abstract values must match. Computations and referenced nodes are
+// compared by value, and errors by message. Nil and empty byte values are
+// identical.
+func (p *Parameter) DeepEquals(param *Parameter) bool {
+	return bytes.Equal(canonical(func(e *encoder) { e.parameter(*p) }), canonical(func(e *encoder) { e.parameter(*param) })) // Return whether or not the parameters are identical
+}
+
+// Hash gets a stable hash of the parameter. Parameters that are deeply equal
+// have the same hash, regardless of the process that computed it.
+func (p *Parameter) Hash() uint64 {
+	return hash(parameterGenome, canonical(func(e *encoder) { e.parameter(*p) })) // Return the hash of the parameter
+}
+
+// DeepEquals checks whether or not two computations are identical.
+func (comp *Computation) DeepEquals(other *Computation) bool {
+	return bytes.Equal(canonical(func(e *encoder) { e.computation(*comp) }), canonical(func(e *encoder) { e.computation(*other) })) // Return whether or not the computations are identical
+}
+
+// Hash gets a stable hash of the computation.
+func (comp *Computation) Hash() uint64 {
+	return hash(computationGenome, canonical(func(e *encoder) { e.computation(*comp) })) // Return the hash of the computation
+}
+
+// DeepEquals checks whether or not two conditional links, including whether
+// or not they are alive and their entire destinations, are identical.
+func (link *ConditionalLink) DeepEquals(other *ConditionalLink) bool {
+	return bytes.Equal(canonical(func(e *encoder) { e.link(*link) }), canonical(func(e *encoder) { e.link(*other) })) // Return whether or not the links are identical
+}
+
+// Hash gets a stable hash of the conditional link.
+func (link *ConditionalLink) Hash() uint64 {
+	return hash(linkGenome, canonical(func(e *encoder) { e.link(*link) })) // Return the hash of the link
+}
+
+// DeepEquals checks whether or not two nodes, including whether or not they
+// are alive and their entire subtrees, are identical.
+func (node *Node) DeepEquals(other *Node) bool {
+	return bytes.Equal(canonical(func(e *encoder) { e.node(*node) }), canonical(func(e *encoder) { e.node(*other) })) // Return whether or not the nodes are identical
+}
+
+// Hash gets a stable hash of the node.
+func (node *Node) Hash() uint64 {
+	return hash(nodeGenome, canonical(func(e *encoder) { e.node(*node) })) // Return the hash of the node
+}
+
+// DeepEquals checks whether or not two nets, including their root nodes,
+// reducers, link modes, and combiners, are identical.
+func (net *Net) DeepEquals(other *Net) bool {
+	return bytes.Equal(canonical(func(e *encoder) { e.net(*net) }), canonical(func(e *encoder) { e.net(*other) })) // Return whether or not the nets are identical
+}
+
+// Hash gets a stable hash of the net.
+func (net *Net) Hash() uint64 {
+	return hash(netGenome, canonical(func(e *encoder) { e.net(*net) })) // Return the hash of the net
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// canonical gets the canonical encoding of the value written by the given
+// callback. Since the encoding has exactly one form for each value, two
+// values are identical if and only if their canonical encodings are.
+func canonical(write func(e *encoder)) []byte {
+	var e encoder // Get an encoder for the value
+
+	write(&e) // Encode the value
+
+	return e.buf // Return the encoded value
+}
+
+// hash gets the 64-bit FNV-1a hash of the given canonical encoding of a
+// value of the given type. The type is mixed into the hash, such that values
+// of different types with the same encoding don't collide.
+func hash(t genomeType, encoded []byte) uint64 {
+	h := fnv.New64a() // Get a hash
+
+	h.Write([]byte{byte(t)}) // Write the type of the value
+	h.Write(encoded)         // Write the encoded value
+
+	return h.Sum64() // Return the hash
+}
+
+/* END INTERNAL METHODS */
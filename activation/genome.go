@@ -0,0 +1,258 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// GenomeVersion is the version of the binary genome format written by the
+// Encode functions. Genomes written in earlier versions of the format can
+// still be decoded. Version 2 added the link mode and combiner of nets.
+const GenomeVersion = 2
+
+// genomeMagic is the sequence of bytes that begins every encoded genome.
+var genomeMagic = []byte("EVE")
+
+var (
+	// ErrNotAGenome is an error definition describing a byte slice that
+	// doesn't begin with the genome magic bytes.
+	ErrNotAGenome = errors.New("not an encoded genome")
+
+	// ErrUnsupportedGenomeVersion is an error definition describing a genome
+	// written in a version of the format that can't be decoded.
+	ErrUnsupportedGenomeVersion = errors.New("unsupported genome version")
+
+	// ErrGenomeTypeMismatch is an error definition describing a genome that
+	// encodes a different type than the one being decoded.
+	ErrGenomeTypeMismatch = errors.New("genome encodes a different type")
+
+	// ErrChecksumMismatch is an error definition describing a genome whose
+	// contents don't match its checksum.
+	ErrChecksumMismatch = errors.New("genome checksum mismatch")
+)
+
+// genomeType identifies the type encoded by a genome.
+type genomeType byte
+
+const (
+	// netGenome is the type of an encoded net.
+	netGenome genomeType = iota + 1
+
+	// nodeGenome is the type of an encoded node.
+	nodeGenome
+
+	// linkGenome is the type of an encoded conditional link.
+	linkGenome
+
+	// computationGenome is the type of an encoded computation.
+	computationGenome
+
+	// parameterGenome is the type of an encoded parameter.
+	parameterGenome
+)
+
+/* BEGIN EXPORTED METHODS */
+
+// EncodeNet encodes the given net as a genome. A genome consists of the
+// magic bytes "EVE", the format version, the encoded type, the length of the
+// encoded value as a varint, the encoded value, and a big-endian CRC-32
+// checksum of all of the preceding bytes.
+func EncodeNet(net Net) []byte {
+	return encodeGenome(netGenome, func(e *encoder) {
+		e.net(net) // Encode the net
+	}) // Return the encoded net
+}
+
+// DecodeNet decodes a net from the given genome.
+func DecodeNet(b []byte) (Net, error) {
+	var net Net // Get a buffer to decode the net into
+
+	err := decodeGenome(b, netGenome, func(d *decoder) {
+		net = d.net() // Decode the net
+	}) // Decode the genome
+
+	return net, err // Return the net
+}
+
+// EncodeNode encodes the given node as a genome.
+func EncodeNode(node Node) []byte {
+	return encodeGenome(nodeGenome, func(e *encoder) {
+		e.node(node) // Encode the node
+	}) // Return the encoded node
+}
+
+// DecodeNode decodes a node from the given genome.
+func DecodeNode(b []byte) (Node, error) {
+	var node Node // Get a buffer to decode the node into
+
+	err := decodeGenome(b, nodeGenome, func(d *decoder) {
+		node = d.node() // Decode the node
+	}) // Decode the genome
+
+	return node, err // Return the node
+}
+
+// EncodeConditionalLink encodes the given conditional link as a genome.
+func EncodeConditionalLink(link ConditionalLink) []byte {
+	return encodeGenome(linkGenome, func(e *encoder) {
+		e.link(link) // Encode the link
+	}) // Return the encoded link
+}
+
+// DecodeConditionalLink decodes a conditional link from the given genome.
+func DecodeConditionalLink(b []byte) (ConditionalLink, error) {
+	var link ConditionalLink // Get a buffer to decode the link into
+
+	err := decodeGenome(b, linkGenome, func(d *decoder) {
+		link = d.link() // Decode the link
+	}) // Decode the genome
+
+	return link, err // Return the link
+}
+
+// EncodeComputation encodes the given computation as a genome.
+func EncodeComputation(comp Computation) []byte {
+	return encodeGenome(computationGenome, func(e *encoder) {
+		e.computation(comp) // Encode the computation
+	}) // Return the encoded computation
+}
+
+// DecodeComputation decodes a computation from the given genome.
+func DecodeComputation(b []byte) (Computation, error) {
+	var comp Computation // Get a buffer to decode the computation into
+
+	err := decodeGenome(b, computationGenome, func(d *decoder) {
+		comp = d.computation() // Decode the computation
+	}) // Decode the genome
+
+	return comp, err // Return the computation
+}
+
+// EncodeParameter encodes the given parameter as a genome. Referenced nodes
+// are encoded by value.
+func EncodeParameter(param Parameter) []byte {
+	return encodeGenome(parameterGenome, func(e *encoder) {
+		e.parameter(param) // Encode the parameter
+	}) // Return the encoded parameter
+}
+
+// DecodeParameter decodes a parameter from the given genome.
+func DecodeParameter(b []byte) (Parameter, error) {
+	var param Parameter // Get a buffer to decode the parameter into
+
+	err := decodeGenome(b, parameterGenome, func(d *decoder) {
+		param = d.parameter() // Decode the parameter
+	}) // Decode the genome
+
+	return param, err // Return the parameter
+}
+
+// MarshalBinary marshals the net to a genome.
+func (net Net) MarshalBinary() ([]byte, error) {
+	return EncodeNet(net), nil // Return the encoded net
+}
+
+// UnmarshalBinary unmarshals a net from the given genome.
+func (net *Net) UnmarshalBinary(b []byte) error {
+	decoded, err := DecodeNet(b) // Decode the net
+	if err != nil {              // Check for errors
+		return err // Return the error
+	}
+
+	*net = decoded // Set the net
+
+	return nil // No error occurred, return nil
+}
+
+// MarshalBinary marshals the parameter to a genome.
+func (p Parameter) MarshalBinary() ([]byte, error) {
+	return EncodeParameter(p), nil // Return the encoded parameter
+}
+
+// UnmarshalBinary unmarshals a parameter from the given genome.
+func (p *Parameter) UnmarshalBinary(b []byte) error {
+	decoded, err := DecodeParameter(b) // Decode the parameter
+	if err != nil {                    // Check for errors
+		return err // Return the error
+	}
+
+	*p = decoded // Set the parameter
+
+	return nil // No error occurred, return nil
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// encodeGenome wraps the value written by the given callback in a genome of
+// the given type.
+func encodeGenome(t genomeType, write func(e *encoder)) []byte {
+	var payload encoder // Get an encoder for the value
+
+	write(&payload) // Encode the value
+
+	e := encoder{buf: append([]byte(nil), genomeMagic...)} // Start the genome with the magic bytes
+
+	e.buf = append(e.buf, GenomeVersion, byte(t)) // Write the version and type
+	e.bytes(payload.buf)                          // Write the length-prefixed value
+
+	var checksum [4]byte // Get a buffer to write the checksum into
+
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(e.buf)) // Write the checksum
+
+	return append(e.buf, checksum[:]...) // Return the genome
+}
+
+// decodeGenome verifies the given genome, and reads its value with the given
+// callback.
+func decodeGenome(b []byte, t genomeType, read func(d *decoder)) error {
+	// Check the genome doesn't begin with the magic bytes
+	if !bytes.HasPrefix(b, genomeMagic) {
+		return ErrNotAGenome // Return the error
+	}
+
+	// Check the genome can't hold a version, type, and checksum
+	if len(b) < len(genomeMagic)+2+4 {
+		return ErrInvalidEncoding // Return the error
+	}
+
+	body, checksum := b[:len(b)-4], b[len(b)-4:] // Split the checksum from the rest of the genome
+
+	// Check the checksum doesn't match
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(checksum) {
+		return ErrChecksumMismatch // Return the error
+	}
+
+	version := body[len(genomeMagic)] // Get the version of the genome
+
+	// Check the version is unsupported
+	if version < 1 || version > GenomeVersion {
+		return ErrUnsupportedGenomeVersion // Return the error
+	}
+
+	// Check the type doesn't match
+	if genomeType(body[len(genomeMagic)+1]) != t {
+		return ErrGenomeTypeMismatch // Return the error
+	}
+
+	framed := decoder{buf: body[len(genomeMagic)+2:]} // Get a decoder for the length-prefixed value
+
+	payload := framed.bytes() // Read the value
+
+	err := framed.finish() // Check the entire genome was consumed
+	if err != nil {        // Check for errors
+		return err // Return the error
+	}
+
+	d := decoder{buf: payload, version: version} // Get a decoder for the value
+
+	read(&d) // Decode the value
+
+	return d.finish() // Check the entire value was consumed
+}
+
+/* END INTERNAL METHODS */
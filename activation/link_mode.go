@@ -0,0 +1,63 @@
+// Package activation implements a simple activation net.
+package activation
+
+import "errors"
+
+// ErrUnknownLinkMode is an error definition describing a link mode name that
+// doesn't correspond to any link mode.
+var ErrUnknownLinkMode = errors.New("unknown link mode")
+
+// LinkMode represents the way in which a node passes its output into its
+// links.
+type LinkMode int
+
+const (
+	// FirstMatch is a link mode in which only the first link whose condition
+	// is satisfied is followed.
+	FirstMatch LinkMode = iota
+
+	// OrderedFanOut is a link mode in which each of the links whose
+	// conditions are satisfied are followed one after another, in the order
+	// of the links. Each branch is given the gas left over by the branches
+	// before it.
+	OrderedFanOut
+
+	// ParallelFanOut is a link mode in which each of the links whose
+	// conditions are satisfied are followed concurrently. The remaining gas
+	// is split evenly between the branches.
+	ParallelFanOut
+)
+
+// linkModeNames maps each link mode to its textual representation.
+var linkModeNames = map[LinkMode]string{
+	FirstMatch:     "first",
+	OrderedFanOut:  "ordered",
+	ParallelFanOut: "parallel",
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// ParseLinkMode gets the link mode with the given name.
+func ParseLinkMode(name string) (LinkMode, error) {
+	// Iterate through the named link modes
+	for mode, modeName := range linkModeNames {
+		// Check the names match
+		if modeName == name {
+			return mode, nil // Return the link mode
+		}
+	}
+
+	return FirstMatch, ErrUnknownLinkMode // Return the error
+}
+
+// String gets the textual representation of the link mode.
+func (mode LinkMode) String() string {
+	return linkModeNames[mode] // Return the name of the link mode
+}
+
+// IsFanOut checks whether or not the link mode follows every satisfied link.
+func (mode LinkMode) IsFanOut() bool {
+	return mode == OrderedFanOut || mode == ParallelFanOut // Return whether or not the mode fans out
+}
+
+/* END EXPORTED METHODS */
@@ -0,0 +1,390 @@
+// Package mutation implements mutation operators for activation nets.
+package mutation
+
+import "github.com/dowlandaiello/eve/activation"
+
+const (
+	// maxDepth is the maximum depth to which mutations are applied. Since
+	// insertion and rewiring can each deepen a net, this prevents high rates
+	// from growing a net indefinitely.
+	maxDepth = 64
+
+	// maxNodes is the number of nodes past which operators that grow a net
+	// (i.e. duplication, rewiring, insertion, and growth) are no longer
+	// applied.
+	maxNodes = 1024
+)
+
+// Rates is a set of mutation rates. Each rate is the probability that its
+// operator is applied to any single site (i.e. node or link) in a net that
+// the operator can be applied to. The revival and growth rates drive the
+// regeneration of links, letting nets repair the damage done by link
+// mortality.
+type Rates struct {
+	Operation float64 // the rate at which a node's operation is replaced (per node)
+
+	Parameter float64 // the rate at which a node's parameter is mutated (per node)
+
+	Condition float64 // the rate at which a link's condition is replaced (per link)
+
+	Comparator float64 // the rate at which a link's comparator is mutated (per link)
+
+	Insertion float64 // the rate at which a node is inserted before a link's destination (per link)
+
+	Deletion float64 // the rate at which a link's destination is removed (per link)
+
+	Rewiring float64 // the rate at which a link is pointed at another node in the net (per link)
+
+	Duplication float64 // the rate at which one of a node's subtrees is duplicated (per node)
+
+	Revival float64 // the rate at which a dead link is brought back to life (per dead link)
+
+	Growth float64 // the rate at which a new random link is grown from a node (per node)
+}
+
+// mutator holds the state of a single application of Mutate.
+type mutator struct {
+	env *activation.Environment // the environment to draw from
+
+	rates Rates // the rates at which to apply each operator
+
+	donors []*activation.Node // the nodes that links may be rewired to
+
+	size int // the current number of nodes in the net
+
+	fired bool // whether or not any of the operators has been applied
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// UniformRates initializes a new set of mutation rates, each of which is set
+// to the given rate. Regeneration (i.e. revival and growth) is left disabled.
+func UniformRates(rate float64) Rates {
+	return Rates{
+		Operation:   rate,
+		Parameter:   rate,
+		Condition:   rate,
+		Comparator:  rate,
+		Insertion:   rate,
+		Deletion:    rate,
+		Rewiring:    rate,
+		Duplication: rate,
+	} // Return the rates
+}
+
+// IsZero checks whether or not none of the operators can be applied.
+func (rates Rates) IsZero() bool {
+	return rates == Rates{} // Return whether or not each of the rates is zero
+}
+
+// Mutate applies each of the mutation operators to a copy of the given net at
+// the given rates, drawing from the given environment. The given net is left
+// untouched. If none of the operators is applied, the given net itself is
+// returned rather than the copy, such that programs compiled from it (see
+// activation.Program.Compiled) stay valid.
+func Mutate(env *activation.Environment, net activation.Net, rates Rates) activation.Net {
+	// Check no operators can be applied
+	if rates.IsZero() {
+		return net // Return the net as-is
+	}
+
+	donors := Nodes(&net) // Get the nodes that links may be rewired to, before the net is changed
+
+	m := mutator{
+		env:    env,    // Set the environment
+		rates:  rates,  // Set the rates
+		donors: donors, // Set the donors
+	} // Get a mutator
+
+	mutated := net.Clone() // Copy the net
+
+	// Iterate through the root nodes
+	for i := range mutated.RootNodes {
+		m.size += size(&mutated.RootNodes[i]) // Add the size of the root node to the initial size of the net
+	}
+
+	// Iterate through the root nodes
+	for i := range mutated.RootNodes {
+		m.mutateNode(&mutated.RootNodes[i], 0) // Mutate the root node
+	}
+
+	// Check none of the operators was applied
+	if !m.fired {
+		return net // Return the net as-is
+	}
+
+	return mutated // Return the mutated net
+}
+
+// Nodes gets each of the nodes in the net that can be activated (i.e. the
+// root nodes, and each of the links' destinations), in depth-first order.
+func Nodes(net *activation.Net) []*activation.Node {
+	var nodes []*activation.Node // Get a buffer to store the nodes in
+
+	var walk func(node *activation.Node) // Declare the walk function so that it may recurse
+
+	walk = func(node *activation.Node) {
+		nodes = append(nodes, node) // Add the node
+
+		// Iterate through the node's links
+		for i := range node.Links {
+			// Check the link has a destination
+			if node.Links[i].HasDestination() {
+				walk(&node.Links[i].Destination) // Walk the destination
+			}
+		}
+	} // Add each of the nodes in the given node's subtree
+
+	// Iterate through the root nodes
+	for i := range net.RootNodes {
+		walk(&net.RootNodes[i]) // Walk the root node
+	}
+
+	return nodes // Return the nodes
+}
+
+// MutateOperation replaces the operation of the given node's function with a
+// random operation.
+func MutateOperation(env *activation.Environment, node *activation.Node) {
+	node.Function.Type = env.Operations.Random(env.Rand) // Replace the operation
+}
+
+// MutateParameter mutates the given parameter. Half of the time, the
+// parameter is replaced with a random parameter; otherwise, its int value is
+// nudged by one, or one of its bits is flipped if it holds bytes.
+func MutateParameter(env *activation.Environment, param *activation.Parameter) {
+	// Check the parameter should be replaced
+	if env.Rand.Intn(2) == 0 {
+		*param = activation.RandomParameter(env) // Replace the parameter
+
+		return // Done
+	}
+
+	// Check the parameter holds bytes
+	if len(param.B) > 0 {
+		param.B = append([]byte(nil), param.B...) // Copy the bytes, so that they can be modified
+
+		param.B[env.Rand.Intn(len(param.B))] ^= 1 << uint(env.Rand.Intn(8)) // Flip a random bit
+
+		return // Done
+	}
+
+	param.I += 2*env.Rand.Intn(2) - 1 // Nudge the int value up or down
+}
+
+// MutateCondition replaces the condition of the given link with a random
+// condition.
+func MutateCondition(env *activation.Environment, link *activation.ConditionalLink) {
+	link.Condition = activation.Condition(env.Rand.Intn(int(activation.Unconditional) + 1)) // Replace the condition
+}
+
+// MutateComparator mutates the comparator of the given link.
+func MutateComparator(env *activation.Environment, link *activation.ConditionalLink) {
+	MutateParameter(env, &link.Comparator) // Mutate the comparator
+}
+
+// InsertNode inserts a new random node between the given link and its
+// destination. The new node unconditionally activates the old destination,
+// if there was one.
+func InsertNode(env *activation.Environment, link *activation.ConditionalLink) {
+	node := activation.NewNode(activation.RandomComputation(env), nil) // Generate the node to insert
+
+	// Check the link has a destination
+	if link.HasDestination() {
+		node.Links = []activation.ConditionalLink{
+			activation.NewConditionalLink(activation.Unconditional, activation.Parameter{}, link.Destination),
+		} // Activate the old destination from the inserted node
+	} else {
+		node.Links = activation.RandomConditionalLinks(env) // Give the node some random links
+	}
+
+	link.Destination = node // Set the destination to the inserted node
+}
+
+// DeleteNode removes the destination of the given link. The link is pointed
+// at a random one of the removed node's destinations, if it has any.
+func DeleteNode(env *activation.Environment, link *activation.ConditionalLink) {
+	var successors []activation.Node // Get a buffer to store the removed node's destinations in
+
+	// Iterate through the destination's links
+	for _, successor := range link.Destination.Links {
+		// Check the link has a destination
+		if successor.HasDestination() {
+			successors = append(successors, successor.Destination) // Add the destination
+		}
+	}
+
+	// Check the removed node has no destinations
+	if len(successors) == 0 {
+		link.Destination = activation.Node{} // Remove the destination
+
+		return // Done
+	}
+
+	link.Destination = successors[env.Rand.Intn(len(successors))] // Point the link at one of the removed node's destinations
+}
+
+// ReviveLink brings the given link back to life.
+func ReviveLink(link *activation.ConditionalLink) {
+	link.Alive = true // Bring the link back to life
+}
+
+// GrowLink adds a new random link to the given node.
+func GrowLink(env *activation.Environment, node *activation.Node) {
+	node.Links = append(node.Links, activation.RandomConditionalLink(env)) // Grow the link
+}
+
+// Rewire points the given link at a copy of a random one of the given nodes.
+func Rewire(env *activation.Environment, link *activation.ConditionalLink, nodes []*activation.Node) {
+	// Check there are no nodes to point the link at
+	if len(nodes) == 0 {
+		return // Nothing to do
+	}
+
+	link.Destination = nodes[env.Rand.Intn(len(nodes))].Clone() // Point the link at a copy of the node
+}
+
+// Duplicate duplicates a random one of the given node's subtrees, adding a
+// copy of the link to the subtree to the node's links.
+func Duplicate(env *activation.Environment, node *activation.Node) {
+	var candidates []int // Get a buffer to store the indices of links with destinations in
+
+	// Iterate through the node's links
+	for i, link := range node.Links {
+		// Check the link has a destination
+		if link.HasDestination() {
+			candidates = append(candidates, i) // Add the index of the link
+		}
+	}
+
+	// Check there are no subtrees to duplicate
+	if len(candidates) == 0 {
+		return // Nothing to do
+	}
+
+	link := node.Links[candidates[env.Rand.Intn(len(candidates))]] // Get the link to duplicate
+
+	node.Links = append(node.Links, link.Clone()) // Add the duplicated subtree to the node's links
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// mutateNode applies each of the mutation operators to the given node, its
+// links, and each of its links' destinations, up to the maximum depth.
+func (m *mutator) mutateNode(node *activation.Node, depth int) {
+	// Check the maximum depth has been reached
+	if depth >= maxDepth {
+		return // Stop mutating
+	}
+
+	// Check the operation should be mutated
+	if m.chance(m.rates.Operation) {
+		MutateOperation(m.env, node) // Mutate the operation
+	}
+
+	// Check the parameter should be mutated
+	if m.chance(m.rates.Parameter) {
+		MutateParameter(m.env, &node.Function.Parameter) // Mutate the parameter
+	}
+
+	// Check a subtree should be duplicated
+	if m.canGrow() && m.chance(m.rates.Duplication) {
+		before := size(node) // Get the size of the node before the duplication
+
+		Duplicate(m.env, node) // Duplicate one of the node's subtrees
+
+		m.size += size(node) - before // Update the size of the net
+	}
+
+	// Check a link should be grown
+	if m.canGrow() && m.chance(m.rates.Growth) {
+		before := size(node) // Get the size of the node before the growth
+
+		GrowLink(m.env, node) // Grow a link from the node
+
+		m.size += size(node) - before // Update the size of the net
+	}
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		link := &node.Links[i] // Get a reference to the link
+
+		// Check the link is dead, and should be revived
+		if !link.Alive && m.chance(m.rates.Revival) {
+			ReviveLink(link) // Revive the link
+		}
+
+		// Check the condition should be mutated
+		if m.chance(m.rates.Condition) {
+			MutateCondition(m.env, link) // Mutate the condition
+		}
+
+		// Check the comparator should be mutated
+		if m.chance(m.rates.Comparator) {
+			MutateComparator(m.env, link) // Mutate the comparator
+		}
+
+		before := size(&link.Destination) // Get the size of the destination before any structural mutations
+
+		// Check the link should be rewired
+		if m.canGrow() && m.chance(m.rates.Rewiring) {
+			Rewire(m.env, link, m.donors) // Rewire the link
+		}
+
+		// Check the destination should be removed
+		if m.chance(m.rates.Deletion) && link.HasDestination() {
+			DeleteNode(m.env, link) // Remove the destination
+		}
+
+		// Check a node should be inserted
+		if m.canGrow() && m.chance(m.rates.Insertion) {
+			InsertNode(m.env, link) // Insert a node
+		}
+
+		m.size += size(&link.Destination) - before // Update the size of the net
+
+		// Check the link has a destination
+		if link.HasDestination() {
+			m.mutateNode(&link.Destination, depth+1) // Mutate the destination
+		}
+	}
+}
+
+// canGrow checks whether or not operators that grow the net may be applied.
+func (m *mutator) canGrow() bool {
+	return m.size < maxNodes // Return whether or not the net is below the maximum size
+}
+
+// chance reports whether or not an event with the given probability occurred.
+// Since each of the operators is guarded by a chance, an occurred event marks
+// the net as mutated.
+func (m *mutator) chance(p float64) bool {
+	occurred := p > 0 && m.env.Rand.Float64() < p // Check whether or not the event occurred
+
+	m.fired = m.fired || occurred // Mark the net as mutated if the event occurred
+
+	return occurred // Return whether or not the event occurred
+}
+
+// size gets the number of nodes in the given node's subtree, including the
+// node itself. Unlike Nodes, this counts nodes that can't be activated, since
+// they still take up space in the net.
+func size(node *activation.Node) int {
+	// Check the node is empty
+	if !node.Alive && len(node.Links) == 0 {
+		return 0 // There is no node
+	}
+
+	n := 1 // Count the node itself
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		n += size(&node.Links[i].Destination) // Count the destination's subtree
+	}
+
+	return n // Return the size of the subtree
+}
+
+/* END INTERNAL METHODS */
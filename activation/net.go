@@ -3,7 +3,6 @@ package activation
 
 import (
 	"math"
-	"math/rand"
 	"sync"
 )
 
@@ -14,6 +13,12 @@ type NetInitializationOption = func(net Net) Net
 // Net is a basic activation net.
 type Net struct {
 	RootNodes []Node // the root nodes of the activation net
+
+	Reducer Reducer // the strategy used to aggregate the outputs of the root nodes
+
+	LinkMode LinkMode // the way in which the net's nodes follow their links
+
+	Combiner Reducer // the strategy used to merge the outputs of the branches followed by fanned out nodes
 }
 
 /* BEGIN EXPORTED METHODS */
@@ -25,11 +30,11 @@ func NewNet(rootNodes []Node) Net {
 	}
 }
 
-// RandomNet initializes a new random net with the given initialization
-// options.
-func RandomNet(opts ...NetInitializationOption) Net {
+// RandomNet initializes a new random net from the given environment with the
+// given initialization options.
+func RandomNet(env *Environment, opts ...NetInitializationOption) Net {
 	net := Net{
-		RootNodes: RandomNodes(), // Set the root nodes of the net to a slice of randomly generated nodes
+		RootNodes: RandomNodes(env), // Set the root nodes of the net to a slice of randomly generated nodes
 	} // Initialize a random net
 
 	// Iterate through the provided options
@@ -40,49 +45,80 @@ func RandomNet(opts ...NetInitializationOption) Net {
 	return net // Return the final net
 }
 
-// Output gets the output of an activation net.
-func (net *Net) Output(params ...Parameter) Parameter {
-	var output LockedParameter // Declare a buffer to store the final output in
+// Output gets the output of an activation net in the given environment. The
+// outputs of each of the root nodes are aggregated by the net's reducer, in
+// the order of the root nodes. The environment's remaining gas is split evenly
+// between the root nodes, and the steps taken by each of them are added to
+// the environment's used gas.
+func (net *Net) Output(env *Environment, params ...Parameter) Parameter {
+	n := len(params) // Get the number of root nodes to evaluate
+
+	// Check there are more params than root nodes
+	if n > len(net.RootNodes) {
+		n = len(net.RootNodes) // Only evaluate the root nodes
+	}
+
+	outputs := make([]Parameter, n) // Get a buffer to store each of the root nodes' outputs in
+	evaluated := make([]bool, n)    // Get a buffer to store whether or not each root node was evaluated
+
+	share := env.Gas.Remaining() // Get the amount of gas given to each root node
+
+	// Check the gas is limited, and there are root nodes to split it between
+	if share != Unlimited && n > 0 {
+		share /= n // Split the gas between the root nodes
+	}
+
+	envs := make([]*Environment, n) // Get a buffer to store each of the root nodes' environments in
 
 	var wg sync.WaitGroup // Get a wait group to handle the outputs w/
 
 	// Iterate through parameters
-	for i, param := range params {
-		// Check param out of bounds
-		if i >= len(net.RootNodes) {
-			break // Break
-		}
+	for i := 0; i < n; i++ {
+		envs[i] = env.Fork()            // Fork the environment before starting the worker, so that each worker is seeded deterministically
+		envs[i].Gas = Gas{Limit: share} // Give the worker its share of the gas
+		envs[i].linkMode = net.LinkMode // Follow links according to the net's link mode
+		envs[i].combiner = net.Combiner // Merge branches with the net's combiner
+		envs[i].traceRoot = i           // Trace the worker's steps under its root node
 
 		wg.Add(1) // Add a worker
 
-		go func(i int, param Parameter, output *LockedParameter, wg *sync.WaitGroup) {
+		go func(i int, env *Environment, param Parameter, wg *sync.WaitGroup) {
+			defer wg.Done() // Signal the worker has finished once done
+
 			// Check the root node is not alive
 			if !net.RootNodes[i].Alive {
-				wg.Done() // Signal the worker has finished
-
 				return // Done
 			}
 
-			evaluatedOutput := net.RootNodes[i].Output(param) // Get the output of the node
+			outputs[i] = net.RootNodes[i].Output(env, param) // Set the output to the current execution
+			evaluated[i] = true                              // Mark the root node as evaluated
+		}(i, envs[i], params[i], &wg)
+	}
 
-			output.Mutex.Lock() // Get a lock for the output
+	wg.Wait() // Wait for the workers to finish
 
-			output.P.Copy(evaluatedOutput) // Set the output to the current execution
+	// Iterate through the workers' environments
+	for _, workerEnv := range envs {
+		env.Gas.Used += workerEnv.Gas.Used // Add the steps taken by the worker
+	}
 
-			output.Mutex.Unlock() // Unlock the output
+	var ordered []Parameter // Get a buffer to store the outputs of the evaluated root nodes in
 
-			wg.Done() // Signal the worker has finished
-		}(i, param, &output, &wg)
+	// Iterate through the outputs
+	for i, output := range outputs {
+		// Check the root node was evaluated
+		if evaluated[i] {
+			ordered = append(ordered, output) // Add the output
+		}
 	}
 
-	wg.Wait() // Wait for the workers to finish
-
-	return output.P // Return the output's parameter
+	return net.Reducer.Reduce(ordered) // Return the aggregated output
 }
 
-// ApplyDecay applies some random amount of decay to the net.
-func (net *Net) ApplyDecay() {
-	i := rand.Intn(int(math.Pow(float64(len(net.RootNodes)), 2.0))) // Get the index of some dead node
+// ApplyDecay applies some random amount of decay to the net, drawing from the
+// given environment.
+func (net *Net) ApplyDecay(env *Environment) {
+	i := env.Rand.Intn(int(math.Pow(float64(len(net.RootNodes)), 2.0))) // Get the index of some dead node
 
 	// Check the index is in range
 	if i < len(net.RootNodes) && i >= 0 {
@@ -90,4 +126,21 @@ func (net *Net) ApplyDecay() {
 	}
 }
 
+// Clone makes a deep copy of the net.
+func (net *Net) Clone() Net {
+	clone := Net{Reducer: net.Reducer, LinkMode: net.LinkMode, Combiner: net.Combiner} // Copy the net's reducer, link mode, and combiner
+
+	// Check the net has root nodes
+	if net.RootNodes != nil {
+		clone.RootNodes = make([]Node, len(net.RootNodes)) // Make a buffer for the copied root nodes
+	}
+
+	// Iterate through the net's root nodes
+	for i := range net.RootNodes {
+		clone.RootNodes[i] = net.RootNodes[i].Clone() // Copy the root node
+	}
+
+	return clone // Return the copied net
+}
+
 /* END EXPORTED METHODS */
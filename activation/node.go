@@ -2,7 +2,7 @@
 package activation
 
 import (
-	"math/rand"
+	"sync"
 
 	"github.com/dowlandaiello/eve/common"
 )
@@ -32,10 +32,10 @@ func NewNode(function Computation, links []ConditionalLink) Node {
 	} // Return the initialized node
 }
 
-// RandomNodes initializes a new random slice of nodes with the given
-// initialization options.
-func RandomNodes(opts ...[]NodeInitializationOption) []Node {
-	n := rand.Intn(common.GlobalEntropy) // Get a random number of nodes to generate
+// RandomNodes initializes a new random slice of nodes from the given
+// environment with the given initialization options.
+func RandomNodes(env *Environment, opts ...[]NodeInitializationOption) []Node {
+	n := env.Rand.Intn(common.GlobalEntropy) // Get a random number of nodes to generate
 
 	var nodes []Node // Declare a buffer to store the generated nodes in
 
@@ -43,24 +43,24 @@ func RandomNodes(opts ...[]NodeInitializationOption) []Node {
 	for i := 0; i < n; i++ {
 		// Check options for the node exist
 		if len(opts) > n {
-			nodes = append(nodes, RandomNode(opts[i]...)) // Add the generated node to the stack of generated nodes
+			nodes = append(nodes, RandomNode(env, opts[i]...)) // Add the generated node to the stack of generated nodes
 
 			continue // Continue
 		}
 
-		nodes = append(nodes, RandomNode()) // Add the generated node to the stack of generated nodes
+		nodes = append(nodes, RandomNode(env)) // Add the generated node to the stack of generated nodes
 	}
 
 	return nodes // Return the generated nodes
 }
 
-// RandomNode initializes a new random node with the given initialization
-// options.
-func RandomNode(opts ...NodeInitializationOption) Node {
+// RandomNode initializes a new random node from the given environment with
+// the given initialization options.
+func RandomNode(env *Environment, opts ...NodeInitializationOption) Node {
 	node := Node{
-		Function: RandomComputation(),      // Set the function to a random computation
-		Links:    RandomConditionalLinks(), // Set the conditional links to a random slice of conditional links
-		Alive:    true,                     // Set the node to alive by default
+		Function: RandomComputation(env),      // Set the function to a random computation
+		Links:    RandomConditionalLinks(env), // Set the conditional links to a random slice of conditional links
+		Alive:    true,                        // Set the node to alive by default
 	} // Initialize a random node
 
 	// Iterate through the provided options
@@ -71,52 +71,187 @@ func RandomNode(opts ...NodeInitializationOption) Node {
 	return node // Return the final node
 }
 
+// IsNone checks whether or not the node is entirely empty (i.e. the
+// destination of a link without a destination), as opposed to a leaf node,
+// or a node that is dead or holds an uninitialized function.
+func (node *Node) IsNone() bool {
+	return !node.Alive && len(node.Links) == 0 && node.Function.Type == Add && node.Function.Parameter.IsZero() // Return whether or not the node is empty
+}
+
 // IsZero checks whether or not the node has been initialized with valid
 // contents.
 func (node *Node) IsZero() bool {
 	return len(node.Links) == 0 || node.Function.IsZero() // Return whether or not the node has a zero value
 }
 
-// Output is the output of the execution of the call stack of the node. NOTE:
+// Output is the output of the execution of the call stack of the node in the
+// given environment. Each node executed takes a single step from the
+// environment's gas; once the gas runs out, ErrOutOfGas is returned. NOTE:
 // This method is not pure, and has the potential to change global state.
-func (node *Node) Output(param Parameter) Parameter {
-	output := node.Function.Execute(param) // Execute the function
+func (node *Node) Output(env *Environment, param Parameter) Parameter {
+	// Check the gas has run out
+	if !env.Gas.Consume() {
+		env.trace(node, param, NewErrorParameter(ErrOutOfGas), nil, nil) // Trace the cut off step
+
+		return NewErrorParameter(ErrOutOfGas) // Cut off the evaluation
+	}
+
+	output := node.Function.Execute(env, param) // Execute the function
 
 	// Check the output is the identity
 	if output.IsIdentity() {
-		return node.doCallstack(Parameter{
-			A: node, // Set the abstract value of the param to the node
-		}) // pass the identity into the call stack
+		return node.doCallstack(env, param, NewNodeParameter(node)) // pass the identity into the call stack
+	}
+
+	return node.doCallstack(env, param, output) // Do the node's call stack
+}
+
+// Clone makes a deep copy of the node. Since a node's links share their
+// backing array with any shallow copies of the node, the node must be cloned
+// before it can be modified independently of those copies.
+func (node *Node) Clone() Node {
+	clone := Node{
+		Function: node.Function.Clone(), // Copy the node's function
+		Alive:    node.Alive,            // Copy whether or not the node is alive
+	} // Copy the node
+
+	// Check the node has links
+	if node.Links != nil {
+		clone.Links = make([]ConditionalLink, len(node.Links)) // Make a buffer for the copied links
+	}
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		clone.Links[i] = node.Links[i].Clone() // Copy the link
 	}
 
-	return node.doCallstack(output) // Do the node's call stack
+	return clone // Return the copied node
 }
 
 /* END EXPORTED METHODS */
 
 /* BEGIN INTERNAL METHODS */
 
-// doCallstack passes a given base output into the node's call stack.
-func (node *Node) doCallstack(baseOutput Parameter) Parameter {
-	// Check no links
-	if len(node.Links) == 0 {
-		return baseOutput // Return the base output
-	}
+// doCallstack passes a given base output, produced from the given input, into
+// the node's call stack. Depending on the environment's link mode, either the
+// first link whose condition is satisfied is followed, or each of them are
+// followed, and the outputs of each of the branches are merged by the
+// environment's combiner.
+func (node *Node) doCallstack(env *Environment, input, baseOutput Parameter) Parameter {
+	var fired, killed []int // Get buffers to store the indices of the followed and killed links in
 
 	// Iterate through the node's links
 	for i, link := range node.Links {
 		// Check that the link is active and has a destination
 		if link.CanActivate(&baseOutput) && link.HasDestination() && !baseOutput.IsError() {
+			fired = append(fired, i) // Follow the link
+
 			// Check the link should be killed
-			if rand.Intn(10) == 0 {
+			if env.LinkMortality > 0 && env.Rand.Float64() < env.LinkMortality {
 				node.Links[i].Alive = false // Kill the link
+
+				killed = append(killed, i) // Record the killed link
 			}
 
-			return link.Destination.Output(baseOutput) // Return the output of the execution
+			// Check only the first link should be followed
+			if !env.linkMode.IsFanOut() {
+				break // Stop looking for links to follow
+			}
+		}
+	}
+
+	env.trace(node, input, baseOutput, fired, killed) // Trace the step
+
+	// Handle the different numbers of followed links
+	switch {
+	case len(fired) == 0:
+		return baseOutput // Return the base output
+	case !env.linkMode.IsFanOut():
+		// Check tracing is enabled
+		if env.Tracer != nil {
+			defer env.descend(fired[0])() // Follow the link in the trace path until the destination returns
+		}
+
+		destination := node.Links[fired[0]].Destination // Get the link's destination
+
+		return destination.Output(env, baseOutput) // Return the output of the execution
+	case env.linkMode == ParallelFanOut && len(fired) > 1 && baseOutput.A.Kind != NodeKind:
+		return node.fanOutParallel(env, baseOutput, fired) // Follow each of the links concurrently
+	default:
+		return node.fanOutOrdered(env, baseOutput, fired) // Follow each of the links in order
+	}
+}
+
+// fanOutOrdered passes the given base output into the destinations of each
+// of the links at the given indices, one after another, and merges their
+// outputs.
+func (node *Node) fanOutOrdered(env *Environment, baseOutput Parameter, fired []int) Parameter {
+	outputs := make([]Parameter, len(fired)) // Get a buffer to store each of the branches' outputs in
+
+	// Iterate through the followed links
+	for j, i := range fired {
+		restore := func() {} // Declare a function to restore the trace path with
+
+		// Check tracing is enabled
+		if env.Tracer != nil {
+			restore = env.descend(i) // Follow the link in the trace path
+		}
+
+		destination := node.Links[i].Destination // Get the link's destination
+
+		outputs[j] = destination.Output(env, baseOutput) // Follow the link
+
+		restore() // Restore the trace path
+	}
+
+	return env.combine(outputs) // Return the merged outputs
+}
+
+// fanOutParallel passes the given base output into the destinations of each
+// of the links at the given indices concurrently, and merges their outputs.
+// Since concurrent branches could otherwise write into the same node, base
+// outputs referencing nodes must be fanned out in order.
+func (node *Node) fanOutParallel(env *Environment, baseOutput Parameter, fired []int) Parameter {
+	outputs := make([]Parameter, len(fired)) // Get a buffer to store each of the branches' outputs in
+
+	share := env.Gas.Remaining() // Get the amount of gas given to each branch
+
+	// Check the gas is limited
+	if share != Unlimited {
+		share /= len(fired) // Split the gas between the branches
+	}
+
+	envs := make([]*Environment, len(fired)) // Get a buffer to store each of the branches' environments in
+
+	var wg sync.WaitGroup // Get a wait group to wait for the branches with
+
+	// Iterate through the followed links
+	for j, i := range fired {
+		envs[j] = env.Fork()            // Fork the environment before starting the worker, so that each worker is seeded deterministically
+		envs[j].Gas = Gas{Limit: share} // Give the worker its share of the gas
+
+		// Check tracing is enabled
+		if env.Tracer != nil {
+			envs[j].descend(i) // Follow the link in the worker's trace path
 		}
+
+		wg.Add(1) // Add a worker
+
+		go func(j int, env *Environment, destination Node) {
+			defer wg.Done() // Signal the worker has finished once done
+
+			outputs[j] = destination.Output(env, baseOutput) // Follow the link
+		}(j, envs[j], node.Links[i].Destination)
+	}
+
+	wg.Wait() // Wait for the workers to finish
+
+	// Iterate through the workers' environments
+	for _, workerEnv := range envs {
+		env.Gas.Used += workerEnv.Gas.Used // Add the steps taken by the worker
 	}
 
-	return baseOutput // Return the base output
+	return env.combine(outputs) // Return the merged outputs
 }
 
 /* END INTERNAL METHODS */
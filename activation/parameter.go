@@ -2,7 +2,7 @@
 package activation
 
 import (
-	"math/rand"
+	"bytes"
 	"sync"
 
 	"github.com/dowlandaiello/eve/common"
@@ -28,7 +28,7 @@ type Parameter struct {
 	B []byte
 
 	// an abstract parameter
-	A interface{} `graphql:"-"`
+	A Abstract
 }
 
 /* BEGIN EXPORTED METHODS */
@@ -36,24 +36,27 @@ type Parameter struct {
 // NewErrorParameter initializes a new abstract parameter with the given error.
 func NewErrorParameter(err error) Parameter {
 	return Parameter{
-		A: err, // Set the abstract value of the param to an error
+		A: Abstract{
+			Kind:  ErrorKind, // Set the kind of the abstract value
+			Error: err,       // Set the abstract value of the param to an error
+		},
 	} // Return the parameter
 }
 
-// RandomParameter initializes a new random parameter with the given
-// initialization options.
-func RandomParameter(opts ...ParameterInitializationOption) Parameter {
+// RandomParameter initializes a new random parameter from the given
+// environment with the given initialization options.
+func RandomParameter(env *Environment, opts ...ParameterInitializationOption) Parameter {
 	var param Parameter // Declare a buffer to store the parameter
 
-	r := rand.Intn(3) // Get a random number
+	r := env.Rand.Intn(3) // Get a random number
 
 	// Check the param should be abstract
 	if r == 0 {
-		param = randomAbstract() // Generate a param with a random abstract value
+		param = randomAbstract(env) // Generate a param with a random abstract value
 	} else if r == 1 {
-		param = randomBytes() // Generate a random parameter with a random byte slice value
+		param = randomBytes(env) // Generate a random parameter with a random byte slice value
 	} else {
-		param = randomInt() // Generate a random parameter with a random int value
+		param = randomInt(env) // Generate a random parameter with a random int value
 	}
 
 	// Iterate through the provided options
@@ -68,6 +71,7 @@ func RandomParameter(opts ...ParameterInitializationOption) Parameter {
 func (p *Parameter) Copy(param Parameter) {
 	// Set each each of the parameter's values to that of the other param
 	p.I = param.I
+	p.B = append([]byte(nil), param.B...)
 	p.A = param.A
 }
 
@@ -92,71 +96,114 @@ func (p *Parameter) Div(param *Parameter) Parameter {
 	return div(*p, *param) // Add the two parameters
 }
 
+// Kind gets the kind of value held by the parameter. Parameters holding an
+// abstract value are of the abstract value's kind; otherwise, parameters
+// holding bytes are of the BytesKind, and all others are of the IntKind.
+func (p *Parameter) Kind() Kind {
+	// Check the parameter has an abstract value
+	if !p.A.IsNone() {
+		return p.A.Kind // Return the kind of the abstract value
+	}
+
+	// Check the parameter has a byte value
+	if len(p.B) > 0 {
+		return BytesKind // The parameter is a byte parameter
+	}
+
+	return IntKind // The parameter is an int parameter
+}
+
 // IsError checks if the parameter is an error.
 func (p *Parameter) IsError() bool {
-	_, ok := p.A.(error) // Check whether or not the abstract value can be cast to an error
-
-	return ok // Return whether or not the cast was successful
+	return p.A.Kind == ErrorKind // Return whether or not the abstract value is an error
 }
 
 // IsIdentity checks if the parameter is requesting the identity.
 func (p *Parameter) IsIdentity() bool {
-	// Check the parameter isn't an error
-	if !p.IsError() {
-		return false // Return false
-	}
-
-	return p.A.(error) == ErrIdentityUnknown // Return whether the error is the identity unknown error
+	return p.IsError() && p.A.Error == ErrIdentityUnknown // Return whether the error is the identity unknown error
 }
 
 // IsZero checks if the parameter has any zero-value fields.
 func (p *Parameter) IsZero() bool {
-	return p.I == 0 && len(p.B) == 0 && p.A == nil // Return whether or not the parameter has any nil fields
+	return p.I == 0 && len(p.B) == 0 && p.A.IsNone() // Return whether or not the parameter has any nil fields
 }
 
 // IsNil checks if the parameter has any nil fields.
 func (p *Parameter) IsNil() bool {
-	return p.A == nil // Return whether or not each field is null
+	return p.A.IsNone() // Return whether or not each field is null
 }
 
 // Equals checks whether or not two parameters are equivalent.
 func (p *Parameter) Equals(param *Parameter) bool {
-	return p.I == param.I || p.A == param.A // Return whether or not these parameters are equivalent
+	return (p.I == param.I && bytes.Equal(p.B, param.B)) || (!p.A.IsNone() && p.A.Equals(&param.A)) // Return whether or not these parameters are equivalent
 }
 
 // LessThan checks whether or not one parameter is less than another parameter.
+// Parameters are ordered by their int values, and then lexicographically by
+// their byte values.
 func (p *Parameter) LessThan(param *Parameter) bool {
-	return p.I < param.I // Return the result
+	return p.compare(param) < 0 // Return the result
 }
 
-// GreaterThan checks whether or not one parameter is greater than another parameter.
+// GreaterThan checks whether or not one parameter is greater than another
+// parameter. Parameters are ordered by their int values, and then
+// lexicographically by their byte values.
 func (p *Parameter) GreaterThan(param *Parameter) bool {
-	return p.I > param.I // Return the result
+	return p.compare(param) > 0 // Return the result
+}
+
+// Clone makes a deep copy of the parameter. Referenced nodes are not copied,
+// since the copy refers to the same node.
+func (p *Parameter) Clone() Parameter {
+	clone := *p // Copy the parameter's values
+
+	clone.B = append([]byte(nil), p.B...) // Copy the parameter's bytes
+
+	// Check the parameter holds a computation
+	if p.A.Kind == ComputationKind && p.A.Computation != nil {
+		comp := p.A.Computation.Clone() // Copy the computation
+
+		clone.A.Computation = &comp // Set the copied computation
+	}
+
+	return clone // Return the copied parameter
 }
 
 /* END EXPORTED METHODS */
 
 /* BEGIN INTERNAL METHODS */
 
+// compare orders two parameters by their int values, and then
+// lexicographically by their byte values. Returns -1 if p < param, 0 if
+// p == param, and 1 if p > param.
+func (p *Parameter) compare(param *Parameter) int {
+	// Check the int values differ
+	if p.I < param.I {
+		return -1 // p is less than param
+	} else if p.I > param.I {
+		return 1 // p is greater than param
+	}
+
+	return bytes.Compare(p.B, param.B) // Compare the byte values
+}
+
 // randomAbstract generates a new parameter with a random abstract value.
-func randomAbstract() Parameter {
-	return Parameter{
-		A: RandomComputation(), // Set the abstract value to be a computation
-	} // Return the abstract parameter
+func randomAbstract(env *Environment) Parameter {
+	return NewComputationParameter(RandomComputation(env)) // Return a parameter whose abstract value is a random computation
 }
 
 // randomInt generates a new parameter with a random int value.
-func randomInt() Parameter {
+func randomInt(env *Environment) Parameter {
 	return Parameter{
-		I: rand.Intn(common.GlobalEntropy), // Generate a random int, set the param's i value to the int
+		I: env.Rand.Intn(common.GlobalEntropy), // Generate a random int, set the param's i value to the int
 	} // Return the parameter
 }
 
 // randomBytes generates a new parameter with a random byte value.
-func randomBytes() Parameter {
+func randomBytes(env *Environment) Parameter {
 	buffer := make([]byte, 4) // Initialize a buffer to read the random byte into
 
-	rand.Read(buffer) // Read a random byte into the buffer
+	env.Rand.Read(buffer) // Read a random byte into the buffer
 
 	return Parameter{
 		B: buffer, // Set the parameter's bytes
@@ -165,24 +212,11 @@ func randomBytes() Parameter {
 
 // add adds two parameters. Leaves the abstract parameter untouched.
 func add(x, y Parameter) Parameter {
-	x.I += y.I // Add the i8s of both parameters
-
-	// Check y has some byte slice contents
-	if len(y.B) > 0 {
-		var nBytes []byte // Get a buffer to store the new byte slice in
+	x.I += y.I // Add the ints of both parameters
 
-		// Iterate through the bytes in b
-		for i, b := range y.B {
-			// Check that the second slice is larger that the receiving param
-			if i >= len(x.B) {
-				nBytes = append(nBytes, b) // Append the byte to the new byte slice
-
-				continue // Continue
-			}
-
-			nBytes = append(nBytes, x.B[i]+b) // Perform the addition operation, add the result ot the new byte slice
-		}
-	}
+	x.B = zipBytes(x.B, y.B, func(a, b byte) byte {
+		return a + b // Add the two bytes
+	}) // Add the bytes of both parameters
 
 	return x // Return the final parmaeter
 }
@@ -191,22 +225,9 @@ func add(x, y Parameter) Parameter {
 func sub(x, y Parameter) Parameter {
 	x.I -= y.I // Subtract the two parameters
 
-	// Check y has some byte slice contents
-	if len(y.B) > 0 {
-		var nBytes []byte // Get a buffer to store the new byte slice in
-
-		// Iterate through the bytes in b
-		for i, b := range y.B {
-			// Check that the second slice is larger that the receiving param
-			if i >= len(x.B) {
-				nBytes = append(nBytes, -b) // Append the negative form of the byte to the new byte slice (0 - n = -n)
-
-				continue // Continue
-			}
-
-			nBytes = append(nBytes, x.B[i]-b) // Perform the addition operation, add the result ot the new byte slice
-		}
-	}
+	x.B = zipBytes(x.B, y.B, func(a, b byte) byte {
+		return a - b // Subtract the two bytes
+	}) // Subtract the bytes of both parameters
 
 	return x // Return the final parameter
 }
@@ -215,49 +236,73 @@ func sub(x, y Parameter) Parameter {
 func mul(x, y Parameter) Parameter {
 	x.I *= y.I // Multiply the two parameters
 
-	// Check y has some byte slice contents
-	if len(y.B) > 0 {
-		var nBytes []byte // Get a buffer to store the new byte slice in
-
-		// Iterate through the bytes in b
-		for i, b := range y.B {
-			// Check that the second slice is larger that the receiving param
-			if i >= len(x.B) {
-				break // All further entries will be zero
-			}
-
-			nBytes = append(nBytes, x.B[i]*b) // Perform the multiplication operation, add the result ot the new byte slice
-		}
-	}
+	x.B = zipBytes(x.B, y.B, func(a, b byte) byte {
+		return a * b // Multiply the two bytes
+	}) // Multiply the bytes of both parameters
 
 	return x // Return the final parameter
 }
 
 // div divides two parameters. Leaves the abstract parameter untouched.
+// Dividing by zero yields zero, both for the int value and for each byte.
 func div(x, y Parameter) Parameter {
 	// Check the second param is zero
 	if y.I == 0 {
-		return Parameter{} // Return a zero-val parameter
+		x.I = 0 // Dividing by zero yields zero
+	} else {
+		x.I /= y.I // Divide the two parameters
+	}
+
+	x.B = zipBytes(x.B, y.B, func(a, b byte) byte {
+		// Check the divisor is zero
+		if b == 0 {
+			return 0 // Dividing by zero yields zero
+		}
+
+		return a / b // Divide the two bytes
+	}) // Divide the bytes of both parameters
+
+	return x // Return the final parameter
+}
+
+// zipBytes applies the given operation to each pair of bytes at the same index
+// in x and y, and returns the results in a new byte slice. The shorter slice
+// is treated as if it were padded with zeroes to the length of the longer
+// slice. Since each result is a byte, overflow wraps around (i.e. results are
+// taken modulo 256).
+func zipBytes(x, y []byte, op func(a, b byte) byte) []byte {
+	n := len(x) // Get the length of the resulting byte slice
+
+	// Check the second slice is longer
+	if len(y) > n {
+		n = len(y) // Use the length of the second slice
+	}
+
+	// Check there are no bytes to operate on
+	if n == 0 {
+		return nil // Return an empty byte slice
 	}
 
-	x.I /= y.I // Divide the two parameters
+	result := make([]byte, n) // Get a buffer to store the new byte slice in
 
-	// Check y has some byte slice contents
-	if len(y.B) > 0 {
-		var nBytes []byte // Get a buffer to store the new byte slice in
+	// Iterate through the indices of the longer slice
+	for i := range result {
+		var a, b byte // Get the bytes at the current index (zero if out of range)
 
-		// Iterate through the bytes in b
-		for i, b := range y.B {
-			// Check that the second slice is larger that the receiving param
-			if i >= len(x.B) {
-				break // All further entries will be zero
-			}
+		// Check the index is in range of the first slice
+		if i < len(x) {
+			a = x[i] // Set the first byte
+		}
 
-			nBytes = append(nBytes, x.B[i]/b) // Perform the division operation, add the result ot the new byte slice
+		// Check the index is in range of the second slice
+		if i < len(y) {
+			b = y[i] // Set the second byte
 		}
+
+		result[i] = op(a, b) // Perform the operation, add the result to the new byte slice
 	}
 
-	return x // Return the final parameter
+	return result // Return the resulting bytes
 }
 
 /* END INTERNAL METHODS */
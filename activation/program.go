@@ -0,0 +1,245 @@
+// Package activation implements a simple activation net.
+package activation
+
+import "math/rand"
+
+// Program is a net compiled to a flat array of instructions, each of which
+// executes a single node. Programs evaluate nets without recursion, and
+// without starting a goroutine for each root node, yielding the same outputs,
+// consuming the same gas, and drawing the same randomness as Net.Output.
+//
+// Instructions refer to the nodes and links of the compiled net, rather than
+// copying them, so that changes made to the net's functions and liveness
+// (e.g. by injection or decay) are seen by the program. A program must be
+// recompiled once the net's structure changes, which is always done by
+// replacing the net with a modified copy (see Compiled). Since a program
+// reuses its buffers between evaluations, it isn't safe for concurrent use.
+//
+// Evaluations don't allocate, beyond what the operations themselves allocate
+// (e.g. to combine byte values): the copies of nodes passed on in place of the
+// identity are kept in a buffer holding a copy for each instruction, and are
+// only moved to the heap when one of them is the output of the evaluation.
+type Program struct {
+	net Net // a shallow copy of the compiled net, sharing its nodes
+
+	instructions []instruction     // the program's instructions, in depth-first order
+	links        []linkInstruction // the links of each of the instructions
+
+	roots []int // the index of the instruction of each of the net's root nodes
+
+	outputs []Parameter // a buffer to store the outputs of the root nodes in
+	args    []Parameter // a buffer to pass the arguments of each operation in
+	copies  []Node      // a buffer to store the copy of the node of each instruction passed on in place of the identity in
+	env     Environment // a buffer to evaluate each of the root nodes in
+	rand    *rand.Rand  // a source of randomness reseeded for each of the root nodes
+}
+
+// instruction executes a single node.
+type instruction struct {
+	node *Node // the node executed by the instruction
+
+	root bool // whether or not the node is a root node
+
+	start, end int // the range of the program's links belonging to the node
+}
+
+// linkInstruction is a link between two instructions.
+type linkInstruction struct {
+	link *ConditionalLink // the compiled link
+
+	destination int // the index of the instruction of the link's destination (-1 if the link can never have one)
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// Compile compiles the given net to a program.
+func Compile(net *Net) *Program {
+	program := &Program{
+		net:   *net,                            // Share the net's nodes
+		roots: make([]int, len(net.RootNodes)), // Make a buffer for the root nodes' instructions
+		rand:  rand.New(rand.NewSource(0)),     // Make a source of randomness to reseed for each root node
+	} // Initialize the program
+
+	// Iterate through the net's root nodes
+	for i := range net.RootNodes {
+		program.roots[i] = program.compile(&net.RootNodes[i], true) // Compile the root node
+	}
+
+	program.copies = make([]Node, len(program.instructions)) // Make room for a copy of each instruction's node
+	program.args = make([]Parameter, 2)                      // Make room for the arguments of each operation
+
+	return program // Return the compiled program
+}
+
+// Compiled checks whether or not the program was compiled from the given net,
+// and may still evaluate it. Since nets are restructured by replacing them
+// with modified copies (e.g. by Clone), a net whose root nodes have been
+// copied is considered to be a different net.
+func (program *Program) Compiled(net *Net) bool {
+	// Check the nets differ in their number of root nodes, or their strategies
+	if len(net.RootNodes) != len(program.net.RootNodes) || net.Reducer != program.net.Reducer || net.LinkMode != program.net.LinkMode || net.Combiner != program.net.Combiner {
+		return false // The program wasn't compiled from the net
+	}
+
+	return len(net.RootNodes) == 0 || &net.RootNodes[0] == &program.net.RootNodes[0] // Return whether or not the nets share their root nodes
+}
+
+// Output gets the output of the compiled net in the given environment. See
+// Net.Output. Since their branches must be merged, nets whose nodes fan out
+// are evaluated by Net.Output, as are traced evaluations.
+func (program *Program) Output(env *Environment, params ...Parameter) Parameter {
+	// Check the evaluation can't be interpreted
+	if env.Tracer != nil || program.net.LinkMode.IsFanOut() {
+		return program.net.Output(env, params...) // Walk the net
+	}
+
+	n := len(params) // Get the number of root nodes to evaluate
+
+	// Check there are more params than root nodes
+	if n > len(program.roots) {
+		n = len(program.roots) // Only evaluate the root nodes
+	}
+
+	share := env.Gas.Remaining() // Get the amount of gas given to each root node
+
+	// Check the gas is limited, and there are root nodes to split it between
+	if share != Unlimited && n > 0 {
+		share /= n // Split the gas between the root nodes
+	}
+
+	program.outputs = program.outputs[:0] // Reset the outputs buffer
+
+	// Iterate through parameters
+	for i := 0; i < n; i++ {
+		program.rand.Seed(env.Rand.Int63()) // Seed the root node's randomness as a fork would be
+
+		program.env = *env                  // Derive the root node's environment
+		program.env.Rand = program.rand     // Use the reseeded randomness
+		program.env.Gas = Gas{Limit: share} // Give the root node its share of the gas
+
+		// Check the root node is not alive
+		if !program.net.RootNodes[i].Alive {
+			continue // Skip the root node
+		}
+
+		program.outputs = append(program.outputs, program.run(&program.env, program.roots[i], params[i])) // Evaluate the root node
+
+		env.Gas.Used += program.env.Gas.Used // Add the steps taken by the root node
+	}
+
+	return program.release(program.net.Reducer.Reduce(program.outputs)) // Return the aggregated output
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// compile compiles the given node and each of its links' possible
+// destinations, returning the index of the node's instruction.
+func (program *Program) compile(node *Node, root bool) int {
+	pc := len(program.instructions) // Get the index of the node's instruction
+
+	program.instructions = append(program.instructions, instruction{node: node, root: root}) // Add the node's instruction
+
+	start := len(program.links) // Get the index of the node's first link
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		program.links = append(program.links, linkInstruction{link: &node.Links[i], destination: -1}) // Add the link
+	}
+
+	program.instructions[pc].start, program.instructions[pc].end = start, len(program.links) // Set the range of the node's links
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		// Check the link's destination has links, and could therefore be activated
+		if len(node.Links[i].Destination.Links) > 0 {
+			program.links[start+i].destination = program.compile(&node.Links[i].Destination, false) // Compile the destination
+		}
+	}
+
+	return pc // Return the index of the node's instruction
+}
+
+// run evaluates the instruction at the given index with the given parameter,
+// and follows the first satisfied link of each instruction thereafter. See
+// Node.Output.
+func (program *Program) run(env *Environment, pc int, param Parameter) Parameter {
+	for {
+		instr := &program.instructions[pc] // Get the instruction
+
+		// Check the gas has run out
+		if !env.Gas.Consume() {
+			return NewErrorParameter(ErrOutOfGas) // Cut off the evaluation
+		}
+
+		output := instr.node.Function.execute(env, param, program.args) // Execute the function
+
+		// Check the output is the identity
+		if output.IsIdentity() {
+			// Check the node is a root node
+			if instr.root {
+				output = NewNodeParameter(instr.node) // Pass the root node itself into the call stack
+			} else {
+				program.copies[pc] = *instr.node // Copy the node, as Node.Output is called on a copy of each destination (each instruction is run at most once per root node, and root nodes don't share instructions)
+
+				output = NewNodeParameter(&program.copies[pc]) // Pass the copied node into the call stack
+			}
+		}
+
+		next := -1 // Get a buffer to store the index of the next instruction in
+
+		// Check the output can be passed on
+		if !output.IsError() {
+			// Iterate through the instruction's links
+			for j := instr.start; j < instr.end; j++ {
+				l := &program.links[j] // Get the link
+
+				// Check that the link is active and has a destination
+				if l.destination >= 0 && l.link.CanActivate(&output) && l.link.HasDestination() {
+					// Check the link should be killed
+					if env.LinkMortality > 0 && env.Rand.Float64() < env.LinkMortality {
+						l.link.Alive = false // Kill the link
+					}
+
+					next = l.destination // Follow the link
+
+					break // Stop looking for links to follow
+				}
+			}
+		}
+
+		// Check no link was followed
+		if next < 0 {
+			return output // Return the output
+		}
+
+		pc, param = next, output // Evaluate the destination
+	}
+}
+
+// release moves the copied node referenced by the given output, if any, out
+// of the program's buffers, such that it isn't overwritten by later
+// evaluations.
+func (program *Program) release(output Parameter) Parameter {
+	// Check the output doesn't reference a node
+	if output.A.Kind != NodeKind {
+		return output // Return the output
+	}
+
+	// Iterate through the copied nodes
+	for i := range program.copies {
+		// Check the output references the copied node
+		if output.A.Node == &program.copies[i] {
+			node := program.copies[i] // Copy the node out of the buffer
+
+			output.A.Node = &node // Reference the copy
+
+			break // Stop iterating
+		}
+	}
+
+	return output // Return the output
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,198 @@
+package activation
+
+import "testing"
+
+// numDifferentialNets is the number of random nets evaluated by each of the
+// differential tests.
+const numDifferentialNets = 500
+
+// TestProgramOutput checks that compiled programs yield the same outputs,
+// consume the same gas, and kill the same links as Net.Output.
+func TestProgramOutput(t *testing.T) {
+	// Iterate through the seeds of the nets to evaluate
+	for seed := int64(0); seed < numDifferentialNets; seed++ {
+		net := RandomNet(NewEnvironment(seed)) // Generate a random net
+
+		checkProgramOutput(t, seed, net, func(env *Environment) {
+			env.LinkMortality = 0.25 // Kill links often enough to diverge if the program draws differently
+		}) // Compare the program with the net
+	}
+}
+
+// TestProgramOutOfGas checks that compiled programs are cut off at the same
+// step as Net.Output once the gas runs out.
+func TestProgramOutOfGas(t *testing.T) {
+	// Iterate through the seeds of the nets to evaluate
+	for seed := int64(0); seed < numDifferentialNets; seed++ {
+		net := RandomNet(NewEnvironment(seed)) // Generate a random net
+
+		limit := int(seed % 8) // Get a gas limit low enough to cut off most evaluations
+
+		checkProgramOutput(t, seed, net, func(env *Environment) {
+			env.Gas = Gas{Limit: limit} // Limit the gas
+		}) // Compare the program with the net
+	}
+}
+
+// TestProgramReusedAfterDecay checks that a compiled program stays valid for
+// its net as root nodes are killed by decay and links by link mortality, and
+// keeps agreeing with Net.Output over several evaluations.
+func TestProgramReusedAfterDecay(t *testing.T) {
+	// Iterate through the seeds of the nets to evaluate
+	for seed := int64(0); seed < numDifferentialNets; seed++ {
+		net := RandomNet(NewEnvironment(seed)) // Generate a random net
+		walked := net.Clone()                  // Copy the net, so that the tree walker's side effects can be compared
+
+		program := Compile(&net) // Compile the net once
+
+		// Evaluate the net over several ticks
+		for tick := int64(0); tick < 5; tick++ {
+			// Check the program no longer considers itself compiled from the net
+			if !program.Compiled(&net) {
+				t.Fatalf("seed %d, tick %d: program invalidated by in-place changes", seed, tick) // Fail
+			}
+
+			params := randomParams(seed, tick, len(net.RootNodes)) // Get the parameters to evaluate the net with
+
+			got, want := evaluationEnvironment(seed, tick), evaluationEnvironment(seed, tick) // Get identical environments for both evaluations
+
+			gotOutput := program.Output(got, params...)  // Evaluate the compiled program
+			wantOutput := walked.Output(want, params...) // Walk the net
+
+			compareEvaluations(t, seed, gotOutput, wantOutput, got, want, &net, &walked) // Compare the evaluations
+
+			// Check there are root nodes to decay
+			if len(net.RootNodes) > 0 {
+				net.ApplyDecay(NewEnvironment(seed + tick))    // Decay the compiled net
+				walked.ApplyDecay(NewEnvironment(seed + tick)) // Decay the walked net in the same way
+			}
+		}
+	}
+}
+
+// checkProgramOutput compares a single evaluation of the compiled net with
+// Net.Output, in environments modified by the given configuration.
+func checkProgramOutput(t *testing.T, seed int64, net Net, configure func(env *Environment)) {
+	t.Helper() // Report failures at the caller
+
+	walked := net.Clone() // Copy the net, so that the tree walker's side effects can be compared
+
+	params := randomParams(seed, 0, len(net.RootNodes)) // Get the parameters to evaluate the net with
+
+	got, want := evaluationEnvironment(seed, 0), evaluationEnvironment(seed, 0) // Get identical environments for both evaluations
+
+	configure(got)  // Configure the program's environment
+	configure(want) // Configure the tree walker's environment
+
+	gotOutput := Compile(&net).Output(got, params...) // Evaluate the compiled program
+	wantOutput := walked.Output(want, params...)      // Walk the net
+
+	compareEvaluations(t, seed, gotOutput, wantOutput, got, want, &net, &walked) // Compare the evaluations
+}
+
+// compareEvaluations fails the test if the given outputs, the gas used by the
+// given environments, or the given nets (after evaluation) differ.
+func compareEvaluations(t *testing.T, seed int64, gotOutput, wantOutput Parameter, got, want *Environment, gotNet, wantNet *Net) {
+	t.Helper() // Report failures at the caller
+
+	// Check the outputs differ
+	if !gotOutput.DeepEquals(&wantOutput) {
+		t.Fatalf("seed %d: program output %+v, net output %+v", seed, gotOutput, wantOutput) // Fail
+	}
+
+	// Check the gas used differs
+	if got.Gas.Used != want.Gas.Used {
+		t.Fatalf("seed %d: program used %d gas, net used %d", seed, got.Gas.Used, want.Gas.Used) // Fail
+	}
+
+	// Check the side effects on the nets differ (e.g. links killed by link mortality)
+	if !gotNet.DeepEquals(wantNet) {
+		t.Fatalf("seed %d: program and net left the net in different states", seed) // Fail
+	}
+}
+
+// evaluationEnvironment gets the environment in which a net is evaluated at
+// the given tick.
+func evaluationEnvironment(seed, tick int64) *Environment {
+	env := NewEnvironment(seed*31 + tick) // Derive the environment from the seed and tick
+	env.LinkMortality = 0.1               // Kill links every now and then
+
+	return env // Return the environment
+}
+
+// randomParams gets the given number of random parameters for the given seed
+// and tick.
+func randomParams(seed, tick int64, n int) []Parameter {
+	env := NewEnvironment(seed*17 + tick) // Derive an environment to draw the parameters from, independently of the evaluation
+
+	params := make([]Parameter, n) // Make a buffer for the parameters
+
+	// Iterate through the parameters
+	for i := range params {
+		params[i] = RandomParameter(env) // Generate the parameter
+	}
+
+	return params // Return the parameters
+}
+
+// TestProgramAllocations checks that evaluating a compiled program doesn't
+// allocate, including when a node other than a root node outputs the
+// identity, as long as its copy doesn't end up in the output.
+func TestProgramAllocations(t *testing.T) {
+	net := identityChain(NewComputation(Inject, NewComputationParameter(NewComputation(Add, Parameter{I: 1})))) // Inject into the copy passed on in place of the identity, so that the output holds a computation
+
+	program := Compile(&net) // Compile the net
+
+	env := NewEnvironment(0) // Get an environment to evaluate the net in
+	env.LinkMortality = 0    // Keep the links alive, so that each evaluation reaches the identity
+
+	params := []Parameter{{I: 1}} // Get the parameters to evaluate the net with
+
+	allocs := testing.AllocsPerRun(100, func() {
+		env.Gas = Gas{Limit: Unlimited} // Reset the gas
+		program.Output(env, params...)  // Evaluate the program
+	}) // Count the allocations made by each evaluation
+
+	// Check the evaluation allocated
+	if allocs != 0 {
+		t.Fatalf("program allocated %v times per evaluation", allocs) // Fail
+	}
+}
+
+// TestProgramReleasesCopies checks that a copied node in the output of a
+// compiled program isn't overwritten by later evaluations.
+func TestProgramReleasesCopies(t *testing.T) {
+	net := identityChain(NewComputation(Add, Parameter{I: 2})) // Pass the copy through to the output
+
+	program := Compile(&net) // Compile the net
+
+	env := NewEnvironment(0) // Get an environment to evaluate the net in
+	env.LinkMortality = 0    // Keep the links alive, so that each evaluation reaches the identity
+
+	first := program.Output(env, Parameter{I: 1}) // Evaluate the program
+
+	// Check the output doesn't reference a node
+	if first.A.Kind != NodeKind {
+		t.Fatalf("output %+v doesn't reference a node", first) // Fail
+	}
+
+	net.RootNodes[0].Links[0].Destination.Function.Parameter.I = 3 // Change the copied node, as an injection would
+
+	program.Output(env, Parameter{I: 1}) // Evaluate the program again
+
+	// Check the first output was overwritten
+	if first.A.Node.Function.Parameter.I != 1 {
+		t.Fatalf("first output's node was overwritten by a later evaluation: %+v", first.A.Node.Function) // Fail
+	}
+}
+
+// identityChain makes a net whose root node passes its output to a node that
+// outputs the identity, which in turn passes a copy of itself to a node with
+// the given function.
+func identityChain(function Computation) Net {
+	leaf := NewNode(function, []ConditionalLink{NewConditionalLink(Unconditional, Parameter{}, Node{})})                                    // Make the node receiving the copy
+	identity := NewNode(NewComputation(Identity, Parameter{I: 1}), []ConditionalLink{NewConditionalLink(Unconditional, Parameter{}, leaf)}) // Make the node outputting the identity
+	root := NewNode(NewComputation(Add, Parameter{I: 1}), []ConditionalLink{NewConditionalLink(Unconditional, Parameter{}, identity)})      // Make the root node
+
+	return NewNet([]Node{root}) // Return the net
+}
@@ -0,0 +1,146 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrUnknownReducer is an error definition describing a reducer name that
+// doesn't correspond to any reducer.
+var ErrUnknownReducer = errors.New("unknown reducer")
+
+// Reducer represents a strategy for aggregating a set of ordered outputs into
+// a single output.
+type Reducer int
+
+const (
+	// LastReducer is a reducer that selects the last output.
+	LastReducer Reducer = iota
+
+	// SumReducer is a reducer that adds each of the outputs together.
+	SumReducer
+
+	// MaxReducer is a reducer that selects the greatest output. Ties are
+	// broken in favor of the earliest output.
+	MaxReducer
+
+	// MajorityReducer is a reducer that selects the output shared by the
+	// most outputs. Ties are broken in favor of the earliest output.
+	MajorityReducer
+
+	// ConcatReducer is a reducer that concatenates each of the outputs into a
+	// single byte parameter. Outputs holding bytes contribute their bytes;
+	// all other outputs contribute their int values as 8 big-endian bytes.
+	ConcatReducer
+)
+
+// reducerNames maps each reducer to its textual representation.
+var reducerNames = map[Reducer]string{
+	LastReducer:     "last",
+	SumReducer:      "sum",
+	MaxReducer:      "max",
+	MajorityReducer: "majority",
+	ConcatReducer:   "concat",
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// ParseReducer gets the reducer with the given name.
+func ParseReducer(name string) (Reducer, error) {
+	// Iterate through the named reducers
+	for reducer, reducerName := range reducerNames {
+		// Check the names match
+		if reducerName == name {
+			return reducer, nil // Return the reducer
+		}
+	}
+
+	return LastReducer, ErrUnknownReducer // Return the error
+}
+
+// String gets the textual representation of the reducer.
+func (reducer Reducer) String() string {
+	return reducerNames[reducer] // Return the name of the reducer
+}
+
+// Reduce aggregates the given outputs into a single output. The outputs must
+// be provided in a deterministic order, as each reducer may depend on it. If
+// no outputs are provided, a zero-value parameter is returned.
+func (reducer Reducer) Reduce(outputs []Parameter) Parameter {
+	// Check no outputs
+	if len(outputs) == 0 {
+		return Parameter{} // Return a zero-value parameter
+	}
+
+	// Handle the different reducers
+	switch reducer {
+	case SumReducer:
+		sum := outputs[0] // Start with the first output
+
+		// Iterate through the remaining outputs
+		for i := 1; i < len(outputs); i++ {
+			sum = sum.Add(&outputs[i]) // Add the output to the sum
+		}
+
+		return sum // Return the sum
+	case MaxReducer:
+		max := outputs[0] // Start with the first output
+
+		// Iterate through the remaining outputs
+		for i := 1; i < len(outputs); i++ {
+			// Check the output is greater than the current max
+			if outputs[i].GreaterThan(&max) {
+				max = outputs[i] // Set the max to the output
+			}
+		}
+
+		return max // Return the max
+	case MajorityReducer:
+		best, bestVotes := 0, 0 // Get the index and votes of the most common output
+
+		// Iterate through the outputs
+		for i := range outputs {
+			votes := 0 // Get a counter for the number of equivalent outputs
+
+			// Iterate through the outputs again
+			for j := range outputs {
+				// Check the outputs are equivalent
+				if outputs[i].Equals(&outputs[j]) {
+					votes++ // Increment the vote counter
+				}
+			}
+
+			// Check the output is more common than the current best
+			if votes > bestVotes {
+				best, bestVotes = i, votes // Set the best output
+			}
+		}
+
+		return outputs[best] // Return the most common output
+	case ConcatReducer:
+		var concatenated []byte // Get a buffer to store the concatenated bytes in
+
+		// Iterate through the outputs
+		for _, output := range outputs {
+			// Check the output holds bytes
+			if len(output.B) > 0 {
+				concatenated = append(concatenated, output.B...) // Add the output's bytes
+
+				continue // Continue
+			}
+
+			var i [8]byte // Get a buffer to write the output's int value into
+
+			binary.BigEndian.PutUint64(i[:], uint64(output.I)) // Write the int value
+
+			concatenated = append(concatenated, i[:]...) // Add the int value
+		}
+
+		return Parameter{B: concatenated} // Return the concatenated bytes
+	default:
+		return outputs[len(outputs)-1] // Return the last output
+	}
+}
+
+/* END EXPORTED METHODS */
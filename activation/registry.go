@@ -0,0 +1,265 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+var (
+	// ErrOperationExists is an error definition describing an attempt to
+	// register an operation under a name that is already taken.
+	ErrOperationExists = errors.New("an operation with the given name already exists")
+
+	// ErrOperationNotFound is an error definition describing a lookup of an
+	// operation that hasn't been registered.
+	ErrOperationNotFound = errors.New("no operation with the given name exists")
+
+	// ErrInvalidArity is an error definition describing an operation that
+	// consumes an unsupported number of arguments.
+	ErrInvalidArity = errors.New("operations must consume between zero and two arguments")
+)
+
+// DefaultOperations is the registry of operations used by environments that
+// haven't been given a registry of their own.
+var DefaultOperations = NewOperationRegistry()
+
+// OperationFunc executes an operation. Depending on the operation's arity,
+// it receives no arguments, the applicant (e.g. 4 in 4 + 2), or the applicant
+// followed by the computation's parameter (e.g. 2 in 4 + 2).
+type OperationFunc = func(args ...Parameter) Parameter
+
+// OperationDefinition describes an operation that can be executed by a
+// computation.
+type OperationDefinition struct {
+	Name string // the name of the operation
+
+	Arity int // the number of arguments consumed by the operation
+
+	Execute OperationFunc // the implementation of the operation
+
+	Weight int // the likelihood of the operation being generated, relative to other operations
+
+	Enabled bool // whether or not the operation can be generated or executed
+}
+
+// OperationRegistry is a set of operations, each identified by the
+// Operation at which it was registered. Since operations are identified by
+// the order in which they were registered, operations must always be
+// registered in the same order for nets to be portable between registries.
+type OperationRegistry struct {
+	operations []OperationDefinition // the registered operations, indexed by operation
+
+	mutex sync.RWMutex // the registry's lock
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewOperationRegistry initializes a new registry containing each of the
+// built-in operations.
+func NewOperationRegistry() *OperationRegistry {
+	registry := &OperationRegistry{} // Initialize an empty registry
+
+	registry.mustRegister(Add, "add", 2, func(args ...Parameter) Parameter {
+		return args[0].Add(&args[1]) // Return the added parameter
+	}) // Register the addition operator
+	registry.mustRegister(Subtract, "sub", 2, func(args ...Parameter) Parameter {
+		return args[0].Sub(&args[1]) // Return the subtracted parameter
+	}) // Register the subtraction operator
+	registry.mustRegister(Multiply, "mul", 2, func(args ...Parameter) Parameter {
+		return args[0].Mul(&args[1]) // Return the multiplied parameter
+	}) // Register the multiplication operator
+	registry.mustRegister(Divide, "div", 2, func(args ...Parameter) Parameter {
+		return args[0].Div(&args[1]) // Return the divided parameter
+	}) // Register the division operator
+	registry.mustRegister(Identity, "identity", 0, func(args ...Parameter) Parameter {
+		return NewErrorParameter(ErrIdentityUnknown) // Return an identity error
+	}) // Register the identity operator
+	registry.mustRegister(Inject, "inject", 2, inject) // Register the injection operator
+
+	return registry // Return the initialized registry
+}
+
+// Register registers a new operation with the given name, arity, execution
+// function, and generation weight. The operation is enabled by default.
+func (registry *OperationRegistry) Register(name string, arity int, execute OperationFunc, weight int) (Operation, error) {
+	// Check the arity is invalid
+	if arity < 0 || arity > 2 {
+		return 0, ErrInvalidArity // Return the error
+	}
+
+	registry.mutex.Lock()         // Lock the registry
+	defer registry.mutex.Unlock() // Unlock the registry once done
+
+	// Check the name is taken
+	if _, ok := registry.lookupName(name); ok {
+		return 0, ErrOperationExists // Return the error
+	}
+
+	registry.operations = append(registry.operations, OperationDefinition{
+		Name:    name,    // Set the name of the operation
+		Arity:   arity,   // Set the arity of the operation
+		Execute: execute, // Set the implementation of the operation
+		Weight:  weight,  // Set the weight of the operation
+		Enabled: true,    // Enable the operation by default
+	}) // Add the operation to the registry
+
+	return Operation(len(registry.operations) - 1), nil // Return the registered operation
+}
+
+// Lookup gets the definition of the given operation.
+func (registry *OperationRegistry) Lookup(op Operation) (OperationDefinition, bool) {
+	registry.mutex.RLock()         // Lock the registry
+	defer registry.mutex.RUnlock() // Unlock the registry once done
+
+	// Check the operation isn't registered
+	if op < 0 || int(op) >= len(registry.operations) {
+		return OperationDefinition{}, false // Return nothing
+	}
+
+	return registry.operations[op], true // Return the operation
+}
+
+// LookupName gets the operation registered under the given name.
+func (registry *OperationRegistry) LookupName(name string) (Operation, bool) {
+	registry.mutex.RLock()         // Lock the registry
+	defer registry.mutex.RUnlock() // Unlock the registry once done
+
+	return registry.lookupName(name) // Return the operation
+}
+
+// Enable allows the operation with the given name to be generated and
+// executed.
+func (registry *OperationRegistry) Enable(name string) error {
+	return registry.setEnabled(name, true) // Enable the operation
+}
+
+// Disable prevents the operation with the given name from being generated
+// or executed. Computations of a disabled operation return their parameter.
+func (registry *OperationRegistry) Disable(name string) error {
+	return registry.setEnabled(name, false) // Disable the operation
+}
+
+// SetWeight sets the generation weight of the operation with the given name.
+func (registry *OperationRegistry) SetWeight(name string, weight int) error {
+	registry.mutex.Lock()         // Lock the registry
+	defer registry.mutex.Unlock() // Unlock the registry once done
+
+	op, ok := registry.lookupName(name) // Get the operation
+	if !ok {                            // Check the operation doesn't exist
+		return ErrOperationNotFound // Return the error
+	}
+
+	registry.operations[op].Weight = weight // Set the weight
+
+	return nil // No error occurred, return nil
+}
+
+// Random picks a random enabled operation, weighted by each operation's
+// generation weight. If no operations can be generated, Add is returned.
+func (registry *OperationRegistry) Random(r *rand.Rand) Operation {
+	registry.mutex.RLock()         // Lock the registry
+	defer registry.mutex.RUnlock() // Unlock the registry once done
+
+	total := 0 // Get a counter for the total weight
+
+	// Iterate through the operations
+	for _, def := range registry.operations {
+		// Check the operation can be generated
+		if def.Enabled && def.Weight > 0 {
+			total += def.Weight // Add the operation's weight to the total
+		}
+	}
+
+	// Check no operations can be generated
+	if total == 0 {
+		return Add // Return the first built-in operation
+	}
+
+	n := r.Intn(total) // Pick a random point in the total weight
+
+	// Iterate through the operations
+	for i, def := range registry.operations {
+		// Check the operation can't be generated
+		if !def.Enabled || def.Weight <= 0 {
+			continue // Continue
+		}
+
+		// Check the point falls within the operation's weight
+		if n < def.Weight {
+			return Operation(i) // Return the operation
+		}
+
+		n -= def.Weight // Move on to the next operation
+	}
+
+	return Add // Unreachable
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// mustRegister registers a built-in operation with a generation weight of
+// one, panicking if it isn't registered at the expected operation.
+func (registry *OperationRegistry) mustRegister(expected Operation, name string, arity int, execute OperationFunc) {
+	op, err := registry.Register(name, arity, execute, 1) // Register the operation
+	if err != nil || op != expected {                     // Check for errors
+		panic("built-in operation registered out of order: " + name) // Panic
+	}
+}
+
+// lookupName gets the operation registered under the given name. Assumes the
+// registry is already locked.
+func (registry *OperationRegistry) lookupName(name string) (Operation, bool) {
+	// Iterate through the operations
+	for i, def := range registry.operations {
+		// Check the names match
+		if def.Name == name {
+			return Operation(i), true // Return the operation
+		}
+	}
+
+	return 0, false // Return nothing
+}
+
+// setEnabled enables or disables the operation with the given name.
+func (registry *OperationRegistry) setEnabled(name string, enabled bool) error {
+	registry.mutex.Lock()         // Lock the registry
+	defer registry.mutex.Unlock() // Unlock the registry once done
+
+	op, ok := registry.lookupName(name) // Get the operation
+	if !ok {                            // Check the operation doesn't exist
+		return ErrOperationNotFound // Return the error
+	}
+
+	registry.operations[op].Enabled = enabled // Set whether or not the operation is enabled
+
+	return nil // No error occurred, return nil
+}
+
+// inject sets the function of the node referenced by the applicant to the
+// computation held by the computation's parameter.
+func inject(args ...Parameter) Parameter {
+	param, function := args[0], args[1] // Get the applicant and the computation's parameter
+
+	// Check parameter has abstract field
+	if !function.A.IsNone() && !param.A.IsNone() {
+		// Check the computation doesn't hold a function to inject
+		if function.A.Kind != ComputationKind || function.A.Computation == nil {
+			return NewErrorParameter(ErrComputationHalted) // Return an err parameter
+		}
+
+		// Check the parameter doesn't hold a node to inject into
+		if param.A.Kind != NodeKind || param.A.Node == nil {
+			return NewErrorParameter(ErrComputationHalted) // Return an err parameter
+		}
+
+		param.A.Node.Function = *function.A.Computation // Set the function of the node
+	}
+
+	return function // Return the computation's parameter
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,124 @@
+// Package activation implements a simple activation net.
+package activation
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TraceStep is a record of the execution of a single node.
+type TraceStep struct {
+	Root int // the index of the root node from which the executed node descends
+
+	Path []int // the indices of the links followed from the root node to the executed node
+
+	Operation string // the name of the executed node's operation
+
+	Input Parameter // the parameter passed into the node
+
+	Output Parameter // the output of the node's function, before being passed into its call stack
+
+	Fired []int // the indices of the links through which the output was passed on
+
+	Killed []int // the indices of the links killed as they fired
+}
+
+// Tracer records each of the steps taken by evaluations in an environment.
+// Tracers are safe for concurrent use.
+type Tracer struct {
+	steps []TraceStep // the recorded steps
+
+	mutex sync.Mutex // the tracer's lock
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewTracer initializes a new tracer with no recorded steps.
+func NewTracer() *Tracer {
+	return &Tracer{} // Return the initialized tracer
+}
+
+// Record records the given step.
+func (tracer *Tracer) Record(step TraceStep) {
+	tracer.mutex.Lock()         // Lock the tracer
+	defer tracer.mutex.Unlock() // Unlock the tracer once done
+
+	tracer.steps = append(tracer.steps, step) // Record the step
+}
+
+// Steps gets each of the recorded steps. Since root nodes are evaluated
+// concurrently, steps are ordered by root node, and then by the order in
+// which they were taken.
+func (tracer *Tracer) Steps() []TraceStep {
+	tracer.mutex.Lock()         // Lock the tracer
+	defer tracer.mutex.Unlock() // Unlock the tracer once done
+
+	steps := append([]TraceStep(nil), tracer.steps...) // Copy the recorded steps
+
+	// Sort the steps by root node
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Root < steps[j].Root // Sort by root node
+	})
+
+	return steps // Return the steps
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// trace records the execution of the given node in the environment's
+// tracer, if the environment has one.
+func (env *Environment) trace(node *Node, input, output Parameter, fired, killed []int) {
+	// Check tracing is disabled
+	if env.Tracer == nil {
+		return // Stop execution
+	}
+
+	name := fmt.Sprintf("op%d", node.Function.Type) // Get the numeric name of the operation
+
+	// Check the operation has a name
+	if def, ok := env.Operations.Lookup(node.Function.Type); ok {
+		name = def.Name // Use the operation's name
+	}
+
+	env.Tracer.Record(TraceStep{
+		Root:      env.traceRoot,                        // Set the index of the root node
+		Path:      append([]int(nil), env.tracePath...), // Copy the path to the node
+		Operation: name,                                 // Set the name of the operation
+		Input:     snapshot(input),                      // Copy the input
+		Output:    snapshot(output),                     // Copy the output
+		Fired:     fired,                                // Set the fired links
+		Killed:    killed,                               // Set the killed links
+	}) // Record the step
+}
+
+// descend updates the environment's trace path to follow the link at the
+// given index, and returns a function that restores the path.
+func (env *Environment) descend(link int) func() {
+	path := env.tracePath // Get the current path
+
+	env.tracePath = append(path[:len(path):len(path)], link) // Follow the link, without writing into the current path's backing array
+
+	return func() {
+		env.tracePath = path // Restore the path
+	} // Return the function restoring the path
+}
+
+// snapshot copies the given parameter, such that it can't be modified by
+// later evaluations. Referenced nodes are copied as well.
+func snapshot(param Parameter) Parameter {
+	clone := param.Clone() // Copy the parameter
+
+	// Check the parameter references a node
+	if clone.A.Kind == NodeKind && clone.A.Node != nil {
+		node := clone.A.Node.Clone() // Copy the node
+
+		clone.A.Node = &node // Set the copied node
+	}
+
+	return clone // Return the copied parameter
+}
+
+/* END INTERNAL METHODS */
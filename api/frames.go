@@ -0,0 +1,114 @@
+// Package api implements GraphQL API for any number of locally running
+// macrocosms.
+package api
+
+import (
+	"encoding/binary"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/dowlandaiello/eve/macrocosm"
+)
+
+// DefaultParticleFrameInterval is the default number of ticks between each of
+// the particle frames recorded by a server.
+const DefaultParticleFrameInterval = 1
+
+// particleFramesBucket is the name of the bucket in which particle frames are
+// stored.
+var particleFramesBucket = []byte("particle_frames")
+
+// FrameStore is a store of the particle frames recorded in a macrocosm's
+// database. Frames are keyed by the big-endian tick at which they were
+// recorded, such that they are iterated in order.
+type FrameStore struct {
+	DB *bolt.DB // the database holding the frames
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewFrameStore initializes a new store of the particle frames recorded in
+// the given database.
+func NewFrameStore(db *bolt.DB) FrameStore {
+	return FrameStore{
+		DB: db, // Set the store's database
+	} // Return the initialized store
+}
+
+// RecordParticleFrame writes the given frame to the store.
+func (store FrameStore) RecordParticleFrame(frame *macrocosm.ParticleFrame) error {
+	json, err := frame.MarshalJSON() // Marshal the frame to a JSON byte slice
+	if err != nil {                  // Check for errors
+		return err // Return the error
+	}
+
+	return store.DB.Update(func(tx *bolt.Tx) error {
+		frames, err := tx.CreateBucketIfNotExists(particleFramesBucket) // Get the frames bucket
+		if err != nil {                                                 // Check for errors
+			return err // Return the error
+		}
+
+		return frames.Put(tickKey(frame.Tick), json) // Put the particle frame in the database
+	}) // Update the database with the new particle frame
+}
+
+// Ticks gets the ticks at which each of the frames in the store were
+// recorded, in ascending order.
+func (store FrameStore) Ticks() ([]int64, error) {
+	var ticks []int64 // Get a buffer to store the ticks in
+
+	err := store.DB.View(func(tx *bolt.Tx) error {
+		frames := tx.Bucket(particleFramesBucket) // Get the frames bucket
+		if frames == nil {                        // Check no frames have been recorded
+			return nil // No frames to list
+		}
+
+		return frames.ForEach(func(k, v []byte) error {
+			ticks = append(ticks, int64(binary.BigEndian.Uint64(k))) // Add the frame's tick
+
+			return nil // No error occurred, return nil
+		}) // Iterate through the frames in the bucket
+	}) // Get the recorded ticks
+
+	return ticks, err // Return the ticks
+}
+
+// ParticleFrame gets the particle frame recorded at the given tick.
+func (store FrameStore) ParticleFrame(tick int64) (*macrocosm.ParticleFrame, error) {
+	var frame *macrocosm.ParticleFrame // Get a buffer to store the frame in
+
+	err := store.DB.View(func(tx *bolt.Tx) error {
+		frames := tx.Bucket(particleFramesBucket) // Get the frames bucket
+		if frames == nil {                        // Check no frames have been recorded
+			return macrocosm.ErrNoParticleFrames // Return the error
+		}
+
+		b := frames.Get(tickKey(tick)) // Get the frame
+		if b == nil {                  // Check no frame was recorded at the tick
+			return macrocosm.ErrTickNotRecorded // Return the error
+		}
+
+		var err error // Declare an error buffer, so that the frame isn't shadowed
+
+		frame, err = macrocosm.UnmarshalParticleFrameJSON(b) // Unmarshal the particle frame
+
+		return err // Return any error
+	}) // Get the particle frame
+
+	return frame, err // Return the frame
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// tickKey gets the key of the frame recorded at the given tick.
+func tickKey(tick int64) []byte {
+	key := make([]byte, 8) // Get a buffer to write the key into
+
+	binary.BigEndian.PutUint64(key, uint64(tick)) // Write the tick
+
+	return key // Return the key
+}
+
+/* END INTERNAL METHODS */
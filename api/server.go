@@ -5,12 +5,16 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/gin-gonic/gin"
 
+	"github.com/dowlandaiello/eve/activation/dot"
 	"github.com/dowlandaiello/eve/common"
 	"github.com/dowlandaiello/eve/macrocosm"
 )
@@ -24,7 +28,11 @@ type Server struct {
 
 	Databases []*bolt.DB // a database used to persist macrocosm frames
 
+	ParticleFrameInterval int64 // the number of ticks between each of the recorded particle frames (disabled if zero)
+
 	Router *gin.Engine // the API router
+
+	ticking []sync.Mutex // the lock held by each of the simulations' loops while a tick is in progress
 }
 
 /* BEGIN EXPORTED METHODS */
@@ -56,9 +64,11 @@ func NewServer(sims []*macrocosm.Macrocosm) (Server, error) {
 	}
 
 	return Server{
-		Simulations: sims,
-		Databases:   databases,
-		Router:      gin.Default(),
+		Simulations:           sims,
+		Databases:             databases,
+		ParticleFrameInterval: DefaultParticleFrameInterval,
+		Router:                gin.Default(),
+		ticking:               make([]sync.Mutex, len(sims)),
 	}, nil // Return the server
 }
 
@@ -66,16 +76,20 @@ func NewServer(sims []*macrocosm.Macrocosm) (Server, error) {
 func (s *Server) Serve(port int) {
 	// Iterate through the simulations
 	for i, sim := range s.Simulations {
-		s.setupRoutesForMacrocosm(sim) // Setup the server for the given simulation
+		s.setupRoutesForMacrocosm(sim, &s.ticking[i]) // Setup the server for the given simulation
 
 		go func(sim *macrocosm.Macrocosm, i int) {
 			for {
 				// start := time.Now() // Get the time at which the macrocosm started expanding
 
+				s.ticking[i].Lock() // Keep snapshots from being taken mid-tick
+
 				sim.Expand() // Expand the macrocosm
 
 				sim.Poll() // Poll the macrocosm
 
+				s.ticking[i].Unlock() // Let snapshots be taken until the next tick
+
 				err := s.Databases[i].Update(func(tx *bolt.Tx) error {
 					// // Check global entropy should be increased
 					// if diff := time.Now().Sub(start).Milliseconds()*2 - common.TimeToExpand.Milliseconds(); diff > common.TimeToExpand.Milliseconds()/2 {
@@ -98,6 +112,7 @@ func (s *Server) Serve(port int) {
 						Shell:                   sim.Shell[:],                   // set the shell
 						ComputationalDifficulty: common.ComputationalDifficulty, // set the computational difficulty
 						GlobalEntropy:           common.GlobalEntropy,           // set the global entropy
+						MeanComplexity:          sim.MeanComplexity(),           // set the mean functional complexity
 					} // Generate a system frame
 
 					json, err := frame.MarshalJSON() // Marshall the frame to a JSON byte slice
@@ -111,6 +126,21 @@ func (s *Server) Serve(port int) {
 				if err != nil {
 					panic(err) // Panic
 				}
+
+				// Check a particle frame should be recorded
+				if s.ParticleFrameInterval > 0 && sim.Tick%s.ParticleFrameInterval == 0 {
+					frame := sim.ParticleFrame() // Capture the macrocosm's particles
+
+					err = NewFrameStore(s.Databases[i]).RecordParticleFrame(&frame) // Record the particle frame
+					if err != nil {                                                 // Check for errors
+						panic(err) // Panic
+					}
+				}
+
+				err = sim.Checkpoint() // Snapshot the macrocosm, if due
+				if err != nil {        // Check for errors
+					panic(err) // Panic
+				}
 			}
 		}(sim, i) // Run a callback that sets up db functionality for the sim
 	}
@@ -122,8 +152,9 @@ func (s *Server) Serve(port int) {
 
 /* BEGIN INTERNAL METHODS */
 
-// setupRoutesForMacrocosm sets up all of the routes for the given macrocosm.
-func (s *Server) setupRoutesForMacrocosm(macrocosm *macrocosm.Macrocosm) {
+// setupRoutesForMacrocosm sets up all of the routes for the given macrocosm,
+// whose loop holds the given lock while a tick is in progress.
+func (s *Server) setupRoutesForMacrocosm(macrocosm *macrocosm.Macrocosm, ticking *sync.Mutex) {
 	s.Router.GET(fmt.Sprintf("%s/sim/macrocosm_%d", rootAPIPath, macrocosm.Identifier), func(c *gin.Context) {
 		json, err := json.Marshal(macrocosm) // Get a JSON response with the macrocosm
 		if err != nil {                      // Check for errors
@@ -133,7 +164,121 @@ func (s *Server) setupRoutesForMacrocosm(macrocosm *macrocosm.Macrocosm) {
 		c.JSON(200, json) // Respond with the JSON
 	}) // Handle the root sim GET
 
-	s.setupSystemRoutesForMacrocosm(fmt.Sprintf("%s/sim/macrocosm_%d", rootAPIPath, macrocosm.Identifier), macrocosm) // Setup system routes
+	s.Router.GET(fmt.Sprintf("%s/sim/macrocosm_%d/snapshot", rootAPIPath, macrocosm.Identifier), func(c *gin.Context) {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=macrocosm_%d.snapshot", macrocosm.Identifier)) // Name the downloaded snapshot
+		c.Header("Content-Type", "application/gzip")                                                                     // Set the type of the snapshot
+
+		ticking.Lock()         // Wait for the current tick to complete, since particles are updated in place while polling
+		defer ticking.Unlock() // Let the macrocosm continue once done
+
+		err := macrocosm.Snapshot(c.Writer) // Write the snapshot
+		if err != nil {                     // Check for errors
+			c.String(http.StatusInternalServerError, err.Error()) // Respond with the error
+		}
+	}) // Handle the snapshot call
+
+	s.setupSystemRoutesForMacrocosm(fmt.Sprintf("%s/sim/macrocosm_%d", rootAPIPath, macrocosm.Identifier), macrocosm)            // Setup system routes
+	s.setupParticleRoutesForMacrocosm(fmt.Sprintf("%s/sim/macrocosm_%d", rootAPIPath, macrocosm.Identifier), macrocosm, ticking) // Setup particle routes
+}
+
+// setupParticleRoutesForMacrocosm sets up all the routes for individual
+// particles in the given macrocosm, whose loop holds the given lock while a
+// tick is in progress.
+func (s *Server) setupParticleRoutesForMacrocosm(path string, sim *macrocosm.Macrocosm, ticking *sync.Mutex) {
+	s.Router.GET(fmt.Sprintf("%s/particle/:x/:y/:z/dot", path), func(c *gin.Context) {
+		vec, err := vectorFromParams(c) // Get the vector of the particle
+		if err != nil {                 // Check for errors
+			c.String(http.StatusBadRequest, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		ticking.Lock() // Wait for the current tick to complete, since particles' nets are updated in place while polling
+
+		particle, ok := sim.HasParticle(vec) // Get the particle at the vector
+
+		net := particle.Net.Clone() // Copy the particle's net, so that it can be exported once the macrocosm continues
+
+		ticking.Unlock() // Let the macrocosm continue
+
+		// Check no particle at the vector
+		if !ok {
+			c.String(http.StatusNotFound, "no particle at vector {%d, %d, %d}", vec.X, vec.Y, vec.Z) // Respond with the error
+
+			return // Stop execution
+		}
+
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(dot.NewExporter(sim.Operations).Export(net))) // Respond with the particle's net as a DOT graph
+	}) // Handle the particle DOT call
+
+	s.Router.PUT(fmt.Sprintf("%s/particle/:x/:y/:z/trace", path), func(c *gin.Context) {
+		vec, err := vectorFromParams(c) // Get the vector of the particle
+		if err != nil {                 // Check for errors
+			c.String(http.StatusBadRequest, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		sim.Trace(vec) // Trace the particle
+
+		c.Status(http.StatusNoContent) // Respond with an empty body
+	}) // Handle the particle trace enable call
+
+	s.Router.DELETE(fmt.Sprintf("%s/particle/:x/:y/:z/trace", path), func(c *gin.Context) {
+		vec, err := vectorFromParams(c) // Get the vector of the particle
+		if err != nil {                 // Check for errors
+			c.String(http.StatusBadRequest, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		sim.Untrace(vec) // Stop tracing the particle
+
+		c.Status(http.StatusNoContent) // Respond with an empty body
+	}) // Handle the particle trace disable call
+
+	s.Router.GET(fmt.Sprintf("%s/particle/:x/:y/:z/trace", path), func(c *gin.Context) {
+		vec, err := vectorFromParams(c) // Get the vector of the particle
+		if err != nil {                 // Check for errors
+			c.String(http.StatusBadRequest, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		trace, ok := sim.ParticleTrace(vec) // Get the particle's most recent trace
+		if !ok {                            // Check the particle hasn't been traced
+			c.String(http.StatusNotFound, "particle at vector {%d, %d, %d} hasn't been traced", vec.X, vec.Y, vec.Z) // Respond with the error
+
+			return // Stop execution
+		}
+
+		json, err := trace.MarshalJSON() // Marshal the trace to a JSON byte slice
+		if err != nil {                  // Check for errors
+			c.String(http.StatusInternalServerError, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		c.Data(http.StatusOK, "application/json; charset=utf-8", json) // Respond with the trace
+	}) // Handle the particle trace call
+}
+
+// vectorFromParams parses a vector from the x, y, and z path parameters of
+// the given request.
+func vectorFromParams(c *gin.Context) (macrocosm.Vector, error) {
+	var values []int64 // Get a buffer to store the parsed coordinates in
+
+	// Iterate through the coordinates
+	for _, name := range []string{"x", "y", "z"} {
+		value, err := strconv.ParseInt(c.Param(name), 10, 64) // Parse the coordinate
+		if err != nil {                                       // Check for errors
+			return macrocosm.Vector{}, fmt.Errorf("invalid %s coordinate: %v", name, err) // Return the error
+		}
+
+		values = append(values, value) // Add the coordinate
+	}
+
+	return macrocosm.NewVectorFromValues(values), nil // Return the vector
 }
 
 // setupSystemRoutesForMacrocosm sets up all the system routes for the given
@@ -162,6 +307,57 @@ func (s *Server) setupSystemRoutesForMacrocosm(path string, sim *macrocosm.Macro
 
 		c.JSON(200, respFrames) // Respond with the frames
 	}) // Handle the system frame call
+
+	s.Router.GET(fmt.Sprintf("%s/particle_frames", path), func(c *gin.Context) {
+		ticks, err := NewFrameStore(s.Databases[sim.Identifier]).Ticks() // Get the recorded ticks
+		if err != nil {                                                  // Check for errors
+			c.String(http.StatusInternalServerError, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		c.JSON(http.StatusOK, ticks) // Respond with the ticks
+	}) // Handle the particle frame list call
+
+	s.Router.GET(fmt.Sprintf("%s/particle_frames/:tick", path), func(c *gin.Context) {
+		tick, err := strconv.ParseInt(c.Param("tick"), 10, 64) // Parse the tick
+		if err != nil {                                        // Check for errors
+			c.String(http.StatusBadRequest, "invalid tick: %v", err) // Respond with the error
+
+			return // Stop execution
+		}
+
+		replay, err := macrocosm.NewReplay(NewFrameStore(s.Databases[sim.Identifier])) // Replay the recorded frames
+		if err == macrocosm.ErrNoParticleFrames {                                      // Check no frames have been recorded
+			c.String(http.StatusNotFound, err.Error()) // Respond with the error
+
+			return // Stop execution
+		} else if err != nil { // Check for errors
+			c.String(http.StatusInternalServerError, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		frame, err := replay.JumpTo(tick)        // Get the most recent frame recorded at or before the tick
+		if err == macrocosm.ErrTickNotRecorded { // Check no frame was recorded at or before the tick
+			c.String(http.StatusNotFound, "%v %d", err, tick) // Respond with the error
+
+			return // Stop execution
+		} else if err != nil { // Check for errors
+			c.String(http.StatusInternalServerError, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		json, err := frame.MarshalJSON() // Marshal the frame to a JSON byte slice
+		if err != nil {                  // Check for errors
+			c.String(http.StatusInternalServerError, err.Error()) // Respond with the error
+
+			return // Stop execution
+		}
+
+		c.Data(http.StatusOK, "application/json; charset=utf-8", json) // Respond with the frame
+	}) // Handle the particle frame call
 }
 
 /* END INTERNAL METHODS */
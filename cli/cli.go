@@ -2,15 +2,28 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/boltdb/bolt"
 	"github.com/juju/loggo"
 	"github.com/juju/loggo/loggocolor"
 	"github.com/urfave/cli"
 
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/activation/asm"
+	"github.com/dowlandaiello/eve/activation/dot"
+	"github.com/dowlandaiello/eve/activation/mutation"
 	"github.com/dowlandaiello/eve/api"
 	"github.com/dowlandaiello/eve/common"
 	"github.com/dowlandaiello/eve/macrocosm"
@@ -39,13 +52,18 @@ func NewCLI() cli.App {
 					return err // Return found error
 				}
 
-				sims := constructSimulations(c) // Generate the provided number of simulations
+				sims, err := constructSimulations(c) // Generate the provided number of simulations
+				if err != nil {                      // Check for errors
+					return err // Return the error
+				}
 
 				server, err := api.NewServer(sims) // Initialize a new server
 				if err != nil {                    // Check for errors
 					return err // Return the error
 				}
 
+				server.ParticleFrameInterval = c.Int64("particle-frame-interval") // Set the number of ticks between each of the recorded particle frames
+
 				server.Serve(c.Int("api-port")) // Start serving
 
 				return nil // No error occurred, return nil
@@ -56,11 +74,138 @@ func NewCLI() cli.App {
 					Usage: "starts serving the API on a given port",
 					Value: 3030,
 				},
+				cli.Int64Flag{
+					Name:  "particle-frame-interval",
+					Usage: "Record the particles of each simulation every given number of ticks, for replay (disabled if zero)",
+					Value: api.DefaultParticleFrameInterval,
+				},
 				cli.IntFlag{
 					Name:  "num-simulations",
 					Usage: "Set the number of simulations to spawn",
 					Value: 1,
 				},
+				cli.Int64Flag{
+					Name:  "seed",
+					Usage: "Seed the simulations' randomness (each simulation is seeded with the given seed plus its identifier; defaults to the current time)",
+				},
+				cli.StringSliceFlag{
+					Name:  "disable-operation",
+					Usage: "Prevent particles from generating or executing the operation with the given name (e.g. inject); may be repeated",
+				},
+				cli.StringFlag{
+					Name:  "reducer",
+					Usage: "Aggregate the outputs of each particle's root nodes with the given reducer (last, sum, max, majority, or concat)",
+					Value: activation.LastReducer.String(),
+				},
+				cli.StringFlag{
+					Name:  "link-mode",
+					Usage: "Follow the links of each particle's nodes in the given mode (first, which follows only the first satisfied link; or ordered or parallel, which follow every satisfied link)",
+					Value: activation.FirstMatch.String(),
+				},
+				cli.StringFlag{
+					Name:  "combiner",
+					Usage: "Merge the branches followed by fanned out nodes with the given reducer (last, sum, max, majority, or concat)",
+					Value: activation.LastReducer.String(),
+				},
+				cli.IntFlag{
+					Name:  "workers",
+					Usage: "Generate and evaluate particles on the given number of workers, shared between the simulations (defaults to the number of CPUs)",
+				},
+				cli.StringFlag{
+					Name:  "execution",
+					Usage: "Schedule particles on the workers in the given mode (pooled, in which each particle is a separate task; chunked, in which each worker is given a contiguous chunk of particles; or sequential, in which no workers are used)",
+					Value: macrocosm.PooledExecution.String(),
+				},
+				cli.StringFlag{
+					Name:  "update",
+					Usage: "Update particles in the given mode (synchronous, in which each particle reads its neighbors' values from the previous tick; or asynchronous, in which particles may read values written earlier in the same tick)",
+					Value: macrocosm.SynchronousUpdate.String(),
+				},
+				cli.StringFlag{
+					Name:  "neighborhood",
+					Usage: "Pass each particle the values of its neighbors in the given neighborhood (adaptive, the smallest cube holding a neighbor for each of the particle's nodes; moore; von-neumann; radius; or offsets)",
+					Value: macrocosm.AdaptiveNeighborhood{}.String(),
+				},
+				cli.Float64Flag{
+					Name:  "neighborhood-radius",
+					Usage: "Make the moore, von-neumann, and radius neighborhoods the given size",
+					Value: macrocosm.DefaultNeighborhoodRadius,
+				},
+				cli.StringSliceFlag{
+					Name:  "neighborhood-offset",
+					Usage: "Add the given offset (of the form x,y,z) to the offsets neighborhood; neighbors are passed in the order of their offsets",
+				},
+				cli.Float64Flag{
+					Name:  "link-mortality",
+					Usage: "Kill each link that fires with the given probability",
+					Value: activation.DefaultLinkMortality,
+				},
+				cli.Float64Flag{
+					Name:  "link-revival-rate",
+					Usage: "Bring each dead link back to life with the given probability after each evaluation",
+				},
+				cli.Float64Flag{
+					Name:  "link-growth-rate",
+					Usage: "Grow a new random link from each node with the given probability after each evaluation",
+				},
+				cli.StringFlag{
+					Name:  "decay",
+					Usage: "Kill the root nodes of each particle after each evaluation according to the given policy (quadratic, complexity, half-life, age, or none)",
+					Value: macrocosm.QuadraticDecay{}.String(),
+				},
+				cli.Float64Flag{
+					Name:  "decay-rate",
+					Usage: "Set the rate of the complexity and age decay policies, or the half-life, in ticks, of the half-life policy (defaults to the policy's default)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate",
+					Usage: "Mutate each site in each particle's net with the given probability after each evaluation",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-operation",
+					Usage: "Replace the operation of each node with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-parameter",
+					Usage: "Mutate the parameter of each node with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-condition",
+					Usage: "Replace the condition of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-comparator",
+					Usage: "Mutate the comparator of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-insertion",
+					Usage: "Insert a node before the destination of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-deletion",
+					Usage: "Remove the destination of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-rewiring",
+					Usage: "Point each link at another node in the net with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-duplication",
+					Usage: "Duplicate one of the subtrees of each node with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.StringSliceFlag{
+					Name:  "resume",
+					Usage: "Resume the simulations from the given snapshot files, in order of identifier; may be repeated",
+				},
+				cli.Int64Flag{
+					Name:  "snapshot-interval",
+					Usage: "Snapshot each simulation to the snapshots path every given number of ticks (disabled if zero)",
+				},
+				cli.StringFlag{
+					Name:  "snapshots-path",
+					Usage: "Store snapshots in a particular path",
+					Value: filepath.FromSlash(fmt.Sprintf("%s/snapshots", common.DataDir)),
+				},
 				cli.BoolFlag{
 					Name:        "disable-log-persistence",
 					Usage:       "Prevent logs from being persisted to the disk",
@@ -89,19 +234,41 @@ func NewCLI() cli.App {
 					return err // Return found error
 				}
 
-				sims := constructSimulations(c) // Generate the provided number of simulations
+				sims, err := constructSimulations(c) // Generate the provided number of simulations
+				if err != nil {                      // Check for errors
+					return err // Return the error
+				}
+
+				ctx, cancel := context.WithCancel(context.Background()) // Get a context to stop the sims with
+				defer cancel()                                          // Stop the sims once done
+
+				interrupts := make(chan os.Signal, 1)   // Get a channel to receive interrupts on
+				signal.Notify(interrupts, os.Interrupt) // Receive interrupts
+
+				go func() {
+					<-interrupts // Wait for an interrupt
+
+					baseLogger.Infof("interrupted; stopping simulations") // Log the interrupt
+
+					cancel() // Stop the sims
+				}() // Stop the sims when interrupted
+
+				var wg sync.WaitGroup // Get a wait group
 
 				// Iterate through the provided sims
 				for _, sim := range sims {
-					// Check is last sim
-					if sim.Identifier == len(sims)-1 {
-						sim.Start() // Start the sim
-					}
+					wg.Add(1) // Wait for the sim
+
+					go func(sim *macrocosm.Macrocosm) {
+						defer wg.Done() // Finish the sim once done
 
-					go sim.Start() // Start the sim
+						sim.StartContext(ctx) // Start the sim
+					}(sim) // Start the sim
 				}
 
-				return nil // This will never happen lol
+				wg.Wait() // Wait for all of the sims to stop
+
+				return nil // No error occurred, return nil
 			},
 			Flags: []cli.Flag{
 				cli.IntFlag{
@@ -109,6 +276,128 @@ func NewCLI() cli.App {
 					Usage: "Set the number of simulations to spawn",
 					Value: 1,
 				},
+				cli.Int64Flag{
+					Name:  "seed",
+					Usage: "Seed the simulations' randomness (each simulation is seeded with the given seed plus its identifier; defaults to the current time)",
+				},
+				cli.StringSliceFlag{
+					Name:  "disable-operation",
+					Usage: "Prevent particles from generating or executing the operation with the given name (e.g. inject); may be repeated",
+				},
+				cli.StringFlag{
+					Name:  "reducer",
+					Usage: "Aggregate the outputs of each particle's root nodes with the given reducer (last, sum, max, majority, or concat)",
+					Value: activation.LastReducer.String(),
+				},
+				cli.StringFlag{
+					Name:  "link-mode",
+					Usage: "Follow the links of each particle's nodes in the given mode (first, which follows only the first satisfied link; or ordered or parallel, which follow every satisfied link)",
+					Value: activation.FirstMatch.String(),
+				},
+				cli.StringFlag{
+					Name:  "combiner",
+					Usage: "Merge the branches followed by fanned out nodes with the given reducer (last, sum, max, majority, or concat)",
+					Value: activation.LastReducer.String(),
+				},
+				cli.IntFlag{
+					Name:  "workers",
+					Usage: "Generate and evaluate particles on the given number of workers, shared between the simulations (defaults to the number of CPUs)",
+				},
+				cli.StringFlag{
+					Name:  "execution",
+					Usage: "Schedule particles on the workers in the given mode (pooled, in which each particle is a separate task; chunked, in which each worker is given a contiguous chunk of particles; or sequential, in which no workers are used)",
+					Value: macrocosm.PooledExecution.String(),
+				},
+				cli.StringFlag{
+					Name:  "update",
+					Usage: "Update particles in the given mode (synchronous, in which each particle reads its neighbors' values from the previous tick; or asynchronous, in which particles may read values written earlier in the same tick)",
+					Value: macrocosm.SynchronousUpdate.String(),
+				},
+				cli.StringFlag{
+					Name:  "neighborhood",
+					Usage: "Pass each particle the values of its neighbors in the given neighborhood (adaptive, the smallest cube holding a neighbor for each of the particle's nodes; moore; von-neumann; radius; or offsets)",
+					Value: macrocosm.AdaptiveNeighborhood{}.String(),
+				},
+				cli.Float64Flag{
+					Name:  "neighborhood-radius",
+					Usage: "Make the moore, von-neumann, and radius neighborhoods the given size",
+					Value: macrocosm.DefaultNeighborhoodRadius,
+				},
+				cli.StringSliceFlag{
+					Name:  "neighborhood-offset",
+					Usage: "Add the given offset (of the form x,y,z) to the offsets neighborhood; neighbors are passed in the order of their offsets",
+				},
+				cli.Float64Flag{
+					Name:  "link-mortality",
+					Usage: "Kill each link that fires with the given probability",
+					Value: activation.DefaultLinkMortality,
+				},
+				cli.Float64Flag{
+					Name:  "link-revival-rate",
+					Usage: "Bring each dead link back to life with the given probability after each evaluation",
+				},
+				cli.Float64Flag{
+					Name:  "link-growth-rate",
+					Usage: "Grow a new random link from each node with the given probability after each evaluation",
+				},
+				cli.StringFlag{
+					Name:  "decay",
+					Usage: "Kill the root nodes of each particle after each evaluation according to the given policy (quadratic, complexity, half-life, age, or none)",
+					Value: macrocosm.QuadraticDecay{}.String(),
+				},
+				cli.Float64Flag{
+					Name:  "decay-rate",
+					Usage: "Set the rate of the complexity and age decay policies, or the half-life, in ticks, of the half-life policy (defaults to the policy's default)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate",
+					Usage: "Mutate each site in each particle's net with the given probability after each evaluation",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-operation",
+					Usage: "Replace the operation of each node with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-parameter",
+					Usage: "Mutate the parameter of each node with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-condition",
+					Usage: "Replace the condition of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-comparator",
+					Usage: "Mutate the comparator of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-insertion",
+					Usage: "Insert a node before the destination of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-deletion",
+					Usage: "Remove the destination of each link with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-rewiring",
+					Usage: "Point each link at another node in the net with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.Float64Flag{
+					Name:  "mutation-rate-duplication",
+					Usage: "Duplicate one of the subtrees of each node with the given probability after each evaluation (overrides mutation-rate)",
+				},
+				cli.StringSliceFlag{
+					Name:  "resume",
+					Usage: "Resume the simulations from the given snapshot files, in order of identifier; may be repeated",
+				},
+				cli.Int64Flag{
+					Name:  "snapshot-interval",
+					Usage: "Snapshot each simulation to the snapshots path every given number of ticks (disabled if zero)",
+				},
+				cli.StringFlag{
+					Name:  "snapshots-path",
+					Usage: "Store snapshots in a particular path",
+					Value: filepath.FromSlash(fmt.Sprintf("%s/snapshots", common.DataDir)),
+				},
 				cli.BoolFlag{
 					Name:        "disable-log-persistence",
 					Usage:       "Prevent logs from being persisted to the disk",
@@ -127,6 +416,101 @@ func NewCLI() cli.App {
 				},
 			},
 		},
+		{
+			Name:      "dot",
+			Usage:     "export an activation net, stored as a genome or in assembly, as a Graphviz DOT graph",
+			ArgsUsage: "<net file>",
+			Action: func(c *cli.Context) error {
+				// Check no file was provided
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "dot") // Show the command's usage
+				}
+
+				net, err := readNet(c.Args().First()) // Read the net
+				if err != nil {                       // Check for errors
+					return err // Return the error
+				}
+
+				graph := dot.Export(net) // Export the net
+
+				// Check no output file was provided
+				if c.String("out") == "" {
+					_, err = fmt.Print(graph) // Write the graph to the standard output
+
+					return err // Return any error
+				}
+
+				return ioutil.WriteFile(c.String("out"), []byte(graph), 0o644) // Write the graph to the output file
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "out",
+					Usage: "Write the graph to the given file rather than the standard output",
+				},
+			},
+		},
+		{
+			Name:      "replay",
+			Usage:     "step backwards and forwards through the particle frames recorded in a simulation's database",
+			ArgsUsage: "<database file>",
+			Action: func(c *cli.Context) error {
+				// Check no database was provided
+				if c.NArg() != 1 {
+					return cli.ShowCommandHelp(c, "replay") // Show the command's usage
+				}
+
+				db, err := bolt.Open(c.Args().First(), 0o644, &bolt.Options{ReadOnly: true, Timeout: 5 * time.Second}) // Open the database
+				if err != nil {                                                                                        // Check for errors
+					return err // Return the error
+				}
+
+				defer db.Close() // Close the database once done
+
+				replay, err := macrocosm.NewReplay(api.NewFrameStore(db)) // Replay the recorded frames
+				if err != nil {                                           // Check for errors
+					return err // Return the error
+				}
+
+				// Check only the recorded ticks should be listed
+				if c.Bool("list") {
+					// Iterate through the recorded ticks
+					for _, tick := range replay.Ticks() {
+						fmt.Println(tick) // Print the tick
+					}
+
+					return nil // No error occurred, return nil
+				}
+
+				// Check a single frame should be printed
+				if c.IsSet("tick") {
+					frame, err := replay.JumpTo(c.Int64("tick")) // Get the frame
+					if err != nil {                              // Check for errors
+						return err // Return the error
+					}
+
+					json, err := frame.MarshalJSON() // Marshal the frame to a JSON byte slice
+					if err != nil {                  // Check for errors
+						return err // Return the error
+					}
+
+					_, err = fmt.Println(string(json)) // Print the frame
+
+					return err // Return any error
+				}
+
+				return stepThroughReplay(replay, os.Stdin, os.Stdout) // Step through the frames interactively
+			},
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "list",
+					Usage: "List the ticks at which frames were recorded",
+				},
+				cli.Int64Flag{
+					Name:  "tick",
+					Usage: "Print the most recent frame recorded at or before the given tick as JSON",
+				},
+			},
+		},
 	}
 
 	return *app // Return the CLI app
@@ -138,23 +522,232 @@ func NewCLI() cli.App {
 
 // constructSimulations generates a slice of macrocosms, according to the
 // provided cli context.
-func constructSimulations(c *cli.Context) []*macrocosm.Macrocosm {
+func constructSimulations(c *cli.Context) ([]*macrocosm.Macrocosm, error) {
 	n := c.Int("num-simulations") // Get the number of simulations to run
 	if n == 0 {                   // Check n is zero
 		n = 1 // Make at least one sim
 	}
 
+	resume := c.StringSlice("resume") // Get the snapshots to resume the simulations from
+	if len(resume) > n {              // Check there are more snapshots than simulations
+		n = len(resume) // Make a sim for each snapshot
+	}
+
+	seed := c.Int64("seed") // Get the base seed of the simulations
+	if !c.IsSet("seed") {   // Check no seed was provided
+		seed = time.Now().UnixNano() // Seed the simulations with the current time
+	}
+
+	baseLogger.Infof("seeding simulations with %d", seed) // Log the seed, so that the run can be reproduced
+
+	reducer, err := activation.ParseReducer(c.String("reducer")) // Get the reducer used by the simulations
+	if err != nil {                                              // Check for errors
+		return nil, err // Return the error
+	}
+
+	linkMode, err := activation.ParseLinkMode(c.String("link-mode")) // Get the link mode used by the simulations
+	if err != nil {                                                  // Check for errors
+		return nil, err // Return the error
+	}
+
+	combiner, err := activation.ParseReducer(c.String("combiner")) // Get the combiner used by the simulations
+	if err != nil {                                                // Check for errors
+		return nil, err // Return the error
+	}
+
+	execution, err := macrocosm.ParseExecutionMode(c.String("execution")) // Get the execution mode used by the simulations
+	if err != nil {                                                       // Check for errors
+		return nil, fmt.Errorf("%s: %v", c.String("execution"), err) // Return the error
+	}
+
+	pool := macrocosm.NewWorkerPool(c.Int("workers")) // Get the pool shared by the simulations
+
+	baseLogger.Infof("scheduling particles on %d workers (%s)", pool.Size(), execution) // Log the pool
+
+	update, err := macrocosm.ParseUpdateMode(c.String("update")) // Get the update mode used by the simulations
+	if err != nil {                                              // Check for errors
+		return nil, fmt.Errorf("%s: %v", c.String("update"), err) // Return the error
+	}
+
+	var offsets []macrocosm.Vector // Initialize a buffer to store the offsets of the neighborhood in
+
+	// Iterate through the offsets of the neighborhood
+	for _, s := range c.StringSlice("neighborhood-offset") {
+		offset, err := macrocosm.ParseOffset(s) // Parse the offset
+		if err != nil {                         // Check for errors
+			return nil, fmt.Errorf("%s: %v", s, err) // Return the error
+		}
+
+		offsets = append(offsets, offset) // Add the offset
+	}
+
+	neighborhood, err := macrocosm.NewNeighborhood(c.String("neighborhood"), c.Float64("neighborhood-radius"), offsets) // Get the neighborhood used by the simulations
+	if err != nil {                                                                                                     // Check for errors
+		return nil, fmt.Errorf("%s: %v", c.String("neighborhood"), err) // Return the error
+	}
+
+	baseLogger.Infof("passing particles the values of their neighbors in the %s neighborhood", neighborhood) // Log the neighborhood
+
+	decay, err := macrocosm.NewDecayPolicy(c.String("decay"), c.Float64("decay-rate")) // Get the decay policy used by the simulations
+	if err != nil {                                                                    // Check for errors
+		return nil, fmt.Errorf("%s: %v", c.String("decay"), err) // Return the error
+	}
+
+	baseLogger.Infof("decaying particles with the %s policy", decay) // Log the decay policy
+
+	// Check the simulations should be snapshotted
+	if c.Int64("snapshot-interval") > 0 {
+		err := common.CreateDirIfNonExistent(c.String("snapshots-path")) // Create the snapshots dir
+		if err != nil {                                                  // Check for errors
+			return nil, err // Return the error
+		}
+	}
+
+	rates := mutation.UniformRates(c.Float64("mutation-rate")) // Get the mutation rates used by the simulations
+
+	// Iterate through the per-operator mutation rates
+	for _, override := range []struct {
+		name string   // the name of the operator
+		rate *float64 // the rate at which the operator is applied
+	}{
+		{"operation", &rates.Operation},
+		{"parameter", &rates.Parameter},
+		{"condition", &rates.Condition},
+		{"comparator", &rates.Comparator},
+		{"insertion", &rates.Insertion},
+		{"deletion", &rates.Deletion},
+		{"rewiring", &rates.Rewiring},
+		{"duplication", &rates.Duplication},
+	} {
+		// Check the operator's rate was set
+		if c.IsSet("mutation-rate-" + override.name) {
+			*override.rate = c.Float64("mutation-rate-" + override.name) // Override the uniform rate
+		}
+	}
+
+	rates.Revival = c.Float64("link-revival-rate") // Set the rate at which dead links are revived
+	rates.Growth = c.Float64("link-growth-rate")   // Set the rate at which links are grown
+
+	baseLogger.Infof("mutating particles at rates %+v", rates) // Log the mutation rates
+
 	var sims []*macrocosm.Macrocosm // Initialize a buffer to store the macrocosms in
 
 	// Make n wait groups
 	for i := 0; i < n; i++ {
-		sim := macrocosm.NewMacrocosm() // Initialize a new simulation
-		sim.Identifier = i              // Set the identifier of the macrocosm
+		sim := macrocosm.NewMacrocosm(seed + int64(i))  // Initialize a new simulation
+		sim.Identifier = i                              // Set the identifier of the macrocosm
+		sim.Reducer = reducer                           // Set the reducer of the macrocosm
+		sim.LinkMode = linkMode                         // Set the link mode of the macrocosm
+		sim.Combiner = combiner                         // Set the combiner of the macrocosm
+		sim.Update = update                             // Set the update mode of the macrocosm
+		sim.Neighborhood = neighborhood                 // Set the neighborhood of the macrocosm
+		sim.Execution = execution                       // Set the execution mode of the macrocosm
+		sim.Pool = pool                                 // Set the pool of the macrocosm
+		sim.Decay = decay                               // Set the decay policy of the macrocosm
+		sim.Mutation = rates                            // Set the mutation rates of the macrocosm
+		sim.LinkMortality = c.Float64("link-mortality") // Set the link mortality of the macrocosm
+
+		sim.SnapshotInterval = c.Int64("snapshot-interval")                                                   // Set the number of ticks between the macrocosm's snapshots
+		sim.SnapshotPath = filepath.Join(c.String("snapshots-path"), fmt.Sprintf("macrocosm_%d.snapshot", i)) // Set the file the macrocosm is snapshotted to
+
+		// Check the simulation should be resumed
+		if i < len(resume) {
+			err := sim.RestoreFile(resume[i]) // Restore the snapshot
+			if err != nil {                   // Check for errors
+				return nil, fmt.Errorf("%s: %v", resume[i], err) // Return the error
+			}
+
+			// Check the snapshot was taken from a different simulation
+			if sim.Identifier != i {
+				return nil, fmt.Errorf("%s: snapshot of macrocosm %d can't resume simulation %d", resume[i], sim.Identifier, i) // Return the error
+			}
+
+			baseLogger.Infof("resuming simulation %d from %s at tick %d", i, resume[i], sim.Tick) // Log the resumed simulation
+		}
+
+		// Iterate through the operations that should be disabled
+		for _, name := range c.StringSlice("disable-operation") {
+			err := sim.Operations.Disable(name) // Disable the operation
+			if err != nil {                     // Check for errors
+				return nil, fmt.Errorf("%s: %v", name, err) // Return the error
+			}
+		}
 
 		sims = append(sims, &sim) // Append the simulation to the array of simulations
 	}
 
-	return sims // Return the initialized simulations
+	return sims, nil // Return the initialized simulations
+}
+
+// stepThroughReplay steps through the given replay according to the commands
+// read from the given reader (next, previous, a tick to jump to, or quit),
+// writing a summary of each frame visited to the given writer.
+func stepThroughReplay(replay *macrocosm.Replay, r io.Reader, w io.Writer) error {
+	frame, err := replay.Frame() // Get the first frame
+	if err != nil {              // Check for errors
+		return err // Return the error
+	}
+
+	scanner := bufio.NewScanner(r) // Get a scanner to read commands with
+
+	for {
+		fmt.Fprintf(w, "tick %d: %d particles, %d alive\n[n]ext, [p]revious, <tick>, or [q]uit: ", frame.Tick, len(frame.Particles), frame.NumAliveParticles()) // Summarize the frame
+
+		// Check there are no more commands
+		if !scanner.Scan() {
+			return scanner.Err() // Return any error
+		}
+
+		var next *macrocosm.ParticleFrame // Get a buffer to store the next frame in
+
+		command := strings.TrimSpace(scanner.Text()) // Get the command
+
+		// Handle the different commands
+		switch command {
+		case "n", "next", "":
+			next, err = replay.Next() // Step forwards
+		case "p", "previous":
+			next, err = replay.Previous() // Step backwards
+		case "q", "quit":
+			return nil // Stop replaying
+		default:
+			tick, parseErr := strconv.ParseInt(command, 10, 64) // Parse the tick to jump to
+			if parseErr != nil {                                // Check for errors
+				fmt.Fprintf(w, "unknown command: %s\n", command) // Print the error
+
+				continue // Read the next command
+			}
+
+			next, err = replay.JumpTo(tick) // Jump to the tick
+		}
+
+		// Check the frame couldn't be visited
+		if err == macrocosm.ErrEndOfReplay || err == macrocosm.ErrTickNotRecorded {
+			fmt.Fprintln(w, err) // Print the error
+
+			continue // Read the next command
+		} else if err != nil { // Check for errors
+			return err // Return the error
+		}
+
+		frame = next // Visit the frame
+	}
+}
+
+// readNet reads a net from the file at the given path. The file may hold
+// either a genome or a net in assembly.
+func readNet(path string) (activation.Net, error) {
+	b, err := ioutil.ReadFile(path) // Read the file
+	if err != nil {                 // Check for errors
+		return activation.Net{}, err // Return the error
+	}
+
+	net, err := activation.DecodeNet(b)  // Try to decode the file as a genome
+	if err == activation.ErrNotAGenome { // Check the file isn't a genome
+		return asm.Parse(string(b)) // Parse the file as assembly
+	}
+
+	return net, err // Return the decoded net
 }
 
 // setupLogging sets up logging for the given cli context.
@@ -0,0 +1,200 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/particle"
+)
+
+const (
+	// DefaultComplexityDecayRate is the default probability, per unit of
+	// functional complexity, that a particle loses a root node in a tick.
+	DefaultComplexityDecayRate = 0.01
+
+	// DefaultHalfLife is the default number of ticks after which half of a
+	// particle's root nodes are expected to have died.
+	DefaultHalfLife = 8.0
+
+	// DefaultAgeDecayRate is the default probability, per tick of age, that a
+	// particle loses a root node in a tick.
+	DefaultAgeDecayRate = 0.05
+)
+
+// ErrUnknownDecayPolicy is an error definition describing a decay policy
+// name that doesn't correspond to any decay policy.
+var ErrUnknownDecayPolicy = errors.New("unknown decay policy")
+
+// DecayPolicy is a rule by which particles' root nodes die after each of
+// their evaluations. Since decay is the simulation's only source of selection
+// pressure, the policy determines which particles survive.
+type DecayPolicy interface {
+	// Decay applies decay to the given particle, drawing from the given
+	// environment.
+	Decay(env *activation.Environment, p *particle.Particle)
+
+	// String gets the textual representation of the policy.
+	String() string
+}
+
+// QuadraticDecay is a decay policy in which a random index is drawn from
+// [0, n^2), where n is the particle's number of root nodes, and the root node
+// at that index, if any, is killed.
+type QuadraticDecay struct{}
+
+// ComplexityDecay is a decay policy in which a particle loses a random live
+// root node with a probability proportional to its functional complexity, as
+// described in spec/eve.md.
+type ComplexityDecay struct {
+	Rate float64 // the probability of losing a root node, per unit of functional complexity
+}
+
+// HalfLifeDecay is a decay policy in which each live root node dies
+// independently, such that half of a particle's root nodes are expected to
+// die within the half-life.
+type HalfLifeDecay struct {
+	HalfLife float64 // the number of ticks after which half of the root nodes are expected to have died
+}
+
+// AgeDecay is a decay policy in which a particle loses a random live root
+// node with a probability proportional to its age.
+type AgeDecay struct {
+	Rate float64 // the probability of losing a root node, per tick of age
+}
+
+// NoDecay is a decay policy in which particles never decay.
+type NoDecay struct{}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewDecayPolicy initializes the decay policy with the given name (quadratic,
+// complexity, half-life, age, or none). The given rate sets the complexity
+// and age policies' rates, and the half-life policy's half-life; if zero, the
+// policy's default is used.
+func NewDecayPolicy(name string, rate float64) (DecayPolicy, error) {
+	// Handle the different policies
+	switch name {
+	case "quadratic":
+		return QuadraticDecay{}, nil // Return the quadratic policy
+	case "complexity":
+		// Check no rate was provided
+		if rate == 0 {
+			rate = DefaultComplexityDecayRate // Use the default rate
+		}
+
+		return ComplexityDecay{Rate: rate}, nil // Return the complexity-proportional policy
+	case "half-life":
+		// Check no half-life was provided
+		if rate == 0 {
+			rate = DefaultHalfLife // Use the default half-life
+		}
+
+		return HalfLifeDecay{HalfLife: rate}, nil // Return the half-life policy
+	case "age":
+		// Check no rate was provided
+		if rate == 0 {
+			rate = DefaultAgeDecayRate // Use the default rate
+		}
+
+		return AgeDecay{Rate: rate}, nil // Return the age-dependent policy
+	case "none":
+		return NoDecay{}, nil // Return the policy that never decays
+	default:
+		return nil, ErrUnknownDecayPolicy // Return the error
+	}
+}
+
+// Decay kills the root node at a random index in [0, n^2), if any.
+func (policy QuadraticDecay) Decay(env *activation.Environment, p *particle.Particle) {
+	p.Net.ApplyDecay(env) // DIE
+}
+
+// String gets the textual representation of the policy.
+func (policy QuadraticDecay) String() string {
+	return "quadratic" // Return the name of the policy
+}
+
+// Decay kills a random live root node with a probability proportional to the
+// particle's functional complexity.
+func (policy ComplexityDecay) Decay(env *activation.Environment, p *particle.Particle) {
+	// Check the particle should lose a root node
+	if env.Rand.Float64() < policy.Rate*float64(p.Complexity) {
+		killRandomRootNode(env, p) // Kill a root node
+	}
+}
+
+// String gets the textual representation of the policy.
+func (policy ComplexityDecay) String() string {
+	return fmt.Sprintf("complexity (rate %g)", policy.Rate) // Return the name of the policy
+}
+
+// Decay kills each live root node with the probability that it dies within a
+// single tick, given the policy's half-life.
+func (policy HalfLifeDecay) Decay(env *activation.Environment, p *particle.Particle) {
+	probability := 1 - math.Pow(2, -1/policy.HalfLife) // Get the probability of a root node dying within a tick
+
+	// Iterate through the particle's root nodes
+	for i := range p.Net.RootNodes {
+		// Check the root node is alive, and should die
+		if p.Net.RootNodes[i].Alive && env.Rand.Float64() < probability {
+			p.Net.RootNodes[i].Alive = false // Kill the root node
+		}
+	}
+}
+
+// String gets the textual representation of the policy.
+func (policy HalfLifeDecay) String() string {
+	return fmt.Sprintf("half-life (%g ticks)", policy.HalfLife) // Return the name of the policy
+}
+
+// Decay kills a random live root node with a probability proportional to the
+// particle's age.
+func (policy AgeDecay) Decay(env *activation.Environment, p *particle.Particle) {
+	// Check the particle should lose a root node
+	if env.Rand.Float64() < policy.Rate*float64(p.Age) {
+		killRandomRootNode(env, p) // Kill a root node
+	}
+}
+
+// String gets the textual representation of the policy.
+func (policy AgeDecay) String() string {
+	return fmt.Sprintf("age (rate %g)", policy.Rate) // Return the name of the policy
+}
+
+// Decay does nothing.
+func (policy NoDecay) Decay(env *activation.Environment, p *particle.Particle) {}
+
+// String gets the textual representation of the policy.
+func (policy NoDecay) String() string {
+	return "none" // Return the name of the policy
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// killRandomRootNode kills a random live root node of the given particle,
+// drawing from the given environment.
+func killRandomRootNode(env *activation.Environment, p *particle.Particle) {
+	var alive []int // Get a buffer to store the indices of the live root nodes in
+
+	// Iterate through the particle's root nodes
+	for i, node := range p.Net.RootNodes {
+		// Check the root node is alive
+		if node.Alive {
+			alive = append(alive, i) // Add the root node's index
+		}
+	}
+
+	// Check no root nodes are alive
+	if len(alive) == 0 {
+		return // Nothing to kill
+	}
+
+	p.Net.RootNodes[alive[env.Rand.Intn(len(alive))]].Alive = false // Kill the root node
+}
+
+/* END INTERNAL METHODS */
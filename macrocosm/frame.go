@@ -16,13 +16,25 @@ type SystemFrame struct {
 	ComputationalDifficulty int // the computational power of the system
 
 	GlobalEntropy int // the system's entropy
+
+	MeanComplexity float64 // the mean functional complexity of the system's live particles
 }
 
 // ParticleFrame is a frame representing the particle state of the system.
 type ParticleFrame struct {
+	Tick int64 // the number of times the system had been polled
+
 	Particles map[Vector]particle.Particle // the particles in the system
 }
 
+// particleFrameJSON is the serialized form of a particle frame. Since vectors
+// can't key JSON objects, the particles are stored as a list of records.
+type particleFrameJSON struct {
+	Tick int64 // the number of times the system had been polled
+
+	Particles []particleRecord // the particles in the system, sorted by location
+}
+
 /* BEGIN EXPORTED METHODS */
 
 // UnmarshalSystemFrameJSON unmarshals a system frame from a given JSON byte
@@ -46,19 +58,39 @@ func (frame *SystemFrame) MarshalJSON() ([]byte, error) {
 // UnmarshalParticleFrameJSON unmarshals a particle frame from a given JSON
 // byte slice.
 func UnmarshalParticleFrameJSON(b []byte) (*ParticleFrame, error) {
-	var frame ParticleFrame // The unmarshalled frame
+	var frame particleFrameJSON // The unmarshalled frame
 
 	err := json.Unmarshal(b, &frame) // Unmarshal the JSON into a frame
 	if err != nil {                  // Check for errors
 		return nil, err // Return the error
 	}
 
-	return &frame, nil // Return the frame
+	particles, err := restoreParticles(frame.Particles) // Restore each of the particles
+	if err != nil {                                     // Check for errors
+		return nil, err // Return the error
+	}
+
+	return &ParticleFrame{Tick: frame.Tick, Particles: particles}, nil // Return the frame
 }
 
 // MarshalJSON marshals the given frame to a JSON byte slice.
 func (frame *ParticleFrame) MarshalJSON() ([]byte, error) {
-	return json.Marshal(frame) // Marshal the frame to JSON
+	return json.Marshal(particleFrameJSON{Tick: frame.Tick, Particles: recordParticles(frame.Particles)}) // Marshal the frame to JSON
+}
+
+// NumAliveParticles gets the number of live particles in the frame.
+func (frame *ParticleFrame) NumAliveParticles() int {
+	n := 0 // Get a counter to increment for each of the live particles
+
+	// Iterate through the frame's particles
+	for _, particle := range frame.Particles {
+		// Check the particle is alive
+		if particle.Alive() {
+			n++ // Count the particle
+		}
+	}
+
+	return n // Return the number of live particles
 }
 
 /* END EXPORTED METHODS */
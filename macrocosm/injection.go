@@ -0,0 +1,175 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"sort"
+
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/activation/analysis"
+	"github.com/dowlandaiello/eve/activation/asm"
+	"github.com/dowlandaiello/eve/activation/mutation"
+)
+
+// Injection is an event in which a particle replaced the function of a node
+// in the net of one of its neighbors.
+type Injection struct {
+	Tick int64 // the tick during which the injection took place
+
+	Source Vector // the location of the injecting particle
+	Target Vector // the location of the particle injected into
+
+	Node int // the index of the replaced node in the target's net, in depth-first order
+
+	Choice int64 // the value with which the injecting particle chose the node to replace (the int of its output)
+
+	Function activation.Computation // the injected function
+}
+
+// neighbor is a particle consulted while evaluating another particle.
+type neighbor struct {
+	vec Vector // the location of the neighbor
+
+	param activation.Parameter // the parameter passed into the evaluation on behalf of the neighbor
+
+	target *activation.Node // the node that the evaluation may inject into on behalf of the neighbor
+}
+
+// stagedFunction is the function held by a staged node that hasn't been
+// injected into.
+var stagedFunction = activation.Computation{Type: -1}
+
+/* BEGIN INTERNAL METHODS */
+
+// stageInjectionTarget gives the evaluation of the particle at the given
+// source a node through which it may inject into the given neighbor. The
+// neighbor's parameter is made to reference the staged node, unless the
+// neighbor's value holds a computation or an error. Since the staged node
+// isn't part of the neighbor's net, the neighbor may be evaluated
+// concurrently; injections are only applied to the neighbor's net once
+// every particle has been evaluated.
+func (macrocosm *Macrocosm) stageInjectionTarget(source Vector, n *neighbor) {
+	// Check the neighbor is the source itself
+	if n.vec == source {
+		return // Particles inject into themselves through their own nodes
+	}
+
+	// Check the neighbor's value holds an abstract value that shouldn't be replaced
+	if !n.param.A.IsNone() && n.param.A.Kind != activation.NodeKind {
+		return // Stop execution
+	}
+
+	// Check the inject operation can't be executed
+	if def, ok := macrocosm.Operations.Lookup(activation.Inject); !ok || !def.Enabled {
+		return // Stop execution
+	}
+
+	n.target = &activation.Node{Function: stagedFunction, Alive: true} // Stage a node to inject into
+	n.param.A = activation.NewNodeParameter(n.target).A                // Reference the staged node
+}
+
+// releaseInjectionTargets strips any reference to the staged nodes of the
+// given neighbors from the given output. Staged nodes aren't part of any net,
+// and computations that don't touch the abstract value of their applicant
+// (e.g. add) pass the reference through to the output, where it would keep
+// the particle from ever being effectively dead.
+func releaseInjectionTargets(output activation.Parameter, neighbors []neighbor) activation.Parameter {
+	// Check the output doesn't reference a node
+	if output.A.Kind != activation.NodeKind {
+		return output // Return the output
+	}
+
+	// Iterate through the neighbors
+	for _, n := range neighbors {
+		// Check the output references the neighbor's staged node
+		if n.target != nil && output.A.Node == n.target {
+			output.A = activation.Abstract{} // Strip the reference
+
+			break // Stop iterating
+		}
+	}
+
+	return output // Return the output
+}
+
+// collectInjection gets the injection made through the staged node of the
+// given neighbor by the particle at the given source, if any. The node
+// replaced in the neighbor is chosen by the int of the given output of the
+// particle.
+func (macrocosm *Macrocosm) collectInjection(source Vector, n neighbor, output activation.Parameter) (Injection, bool) {
+	// Check nothing was injected (staged nodes hold an unknown operation until injected into)
+	if n.target == nil || n.target.Function.Type < 0 {
+		return Injection{}, false // Return nothing
+	}
+
+	return Injection{
+		Tick:     macrocosm.Tick,            // Set the tick of the injection
+		Source:   source,                    // Set the source of the injection
+		Target:   n.vec,                     // Set the target of the injection
+		Function: n.target.Function.Clone(), // Set the injected function
+		Choice:   int64(output.I),           // Set the value choosing the node to replace
+	}, true // Return the injection
+}
+
+// applyInjections applies each of the given injections to the macrocosm, in
+// order of their targets, and then of their sources. The node replaced in
+// each target is the node at the injection's choice (modulo the number of
+// nodes in the target's net, in depth-first order), so the outcome doesn't
+// depend on the order in which particles were evaluated. Returns the applied
+// injections.
+func (macrocosm *Macrocosm) applyInjections(injections []Injection) []Injection {
+	// Sort the injections by target, and then by source
+	sort.Slice(injections, func(i, j int) bool {
+		// Check the targets are the same
+		if injections[i].Target == injections[j].Target {
+			return injections[i].Source.Less(injections[j].Source) // Sort by source
+		}
+
+		return injections[i].Target.Less(injections[j].Target) // Sort by target
+	})
+
+	macrocosm.Lock.Lock()         // Lock the macrocosm
+	defer macrocosm.Lock.Unlock() // Unlock the macrocosm once done
+
+	var applied []Injection // Get a buffer to store the applied injections in
+
+	// Iterate through the injections
+	for _, injection := range injections {
+		target, ok := macrocosm.Particles.Get(injection.Target) // Get the target particle
+
+		// Check the target is missing or dead
+		if !ok || !target.Alive() {
+			continue // Continue
+		}
+
+		net := target.Net.Clone() // Copy the target's net, since its backing arrays may be shared
+
+		nodes := mutation.Nodes(&net) // Get the nodes that could be replaced
+
+		// Check there's nothing to inject into
+		if len(nodes) == 0 {
+			continue // Continue
+		}
+
+		injection.Node = int(injection.Choice % int64(len(nodes))) // Get the node chosen by the injecting particle
+
+		// Check the choice was negative
+		if injection.Node < 0 {
+			injection.Node += len(nodes) // Wrap around
+		}
+
+		nodes[injection.Node].Function = injection.Function.Clone() // Replace the node's function
+
+		target.Net = net                                                          // Set the target's net
+		target.Complexity = analysis.Complexity(macrocosm.Operations, target.Net) // Measure the target's functional complexity
+
+		macrocosm.Particles.Put(injection.Target, target) // Put the target back in the macrocosm
+
+		macrocosm.logger.Infof("particle at vector {%d, %d, %d} injected %s into node %d of particle at vector {%d, %d, %d}", injection.Source.X, injection.Source.Y, injection.Source.Z, asm.NewAssembler(macrocosm.Operations).FormatComputation(injection.Function), injection.Node, injection.Target.X, injection.Target.Y, injection.Target.Z) // Log the injection
+
+		applied = append(applied, injection) // Record the injection
+	}
+
+	return applied // Return the applied injections
+}
+
+/* END INTERNAL METHODS */
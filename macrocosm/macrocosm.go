@@ -2,16 +2,32 @@
 package macrocosm
 
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"math"
 	"sync"
 
 	"github.com/juju/loggo"
 
 	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/activation/analysis"
+	"github.com/dowlandaiello/eve/activation/mutation"
 	"github.com/dowlandaiello/eve/particle"
 )
 
+// stage is a step in a macrocosm's tick that consumes randomness.
+type stage int64
+
+const (
+	// expansionStage is the stage in which new particles are generated.
+	expansionStage stage = iota
+
+	// pollingStage is the stage in which particles are evaluated.
+	pollingStage
+)
+
 // FlattenedMacrocosm is an API-friendly macrocosm copy.
 type FlattenedMacrocosm struct {
 	Particles [][][]particle.Particle `json:"-"` // the macrocosm's particles
@@ -24,32 +40,86 @@ type FlattenedMacrocosm struct {
 
 // Macrocosm is a macrocosm, as defined in spec/eve.md.
 type Macrocosm struct {
-	Particles map[Vector]particle.Particle `json:"-"` // the macrocosm's particles
+	Particles ParticleStore `json:"-"` // the macrocosm's particles
 
 	Head  [2]Vector // the outermost non-nil particle
 	Shell [2]Vector // the outermost nil particle that should be created in the next round
 
 	Identifier int // the identifier of the macrocosm
 
+	Seed int64 // the seed from which all of the macrocosm's randomness is derived
+	Tick int64 // the number of times the macrocosm has been polled
+
+	Operations *activation.OperationRegistry `json:"-"` // the operations that particles in the macrocosm can generate and execute
+
+	Reducer activation.Reducer // the strategy used by generated particles to aggregate the outputs of their root nodes
+
+	LinkMode activation.LinkMode // the way in which the nodes of generated particles follow their links
+	Combiner activation.Reducer  // the strategy used by generated particles to merge the branches of fanned out nodes
+
+	Mutation mutation.Rates // the rates at which particles' nets are mutated after each evaluation
+
+	LinkMortality float64 // the probability that a link is killed as it fires
+
+	Update UpdateMode // the way in which particles evaluated during a poll see each other's new values
+
+	Neighborhood Neighborhood `json:"-"` // the rule determining the locations from which particles take their inputs (the adaptive neighborhood if nil)
+
+	Execution ExecutionMode `json:"-"` // the way in which particles are generated and evaluated concurrently
+	Pool      *WorkerPool   `json:"-"` // the pool on which particles are generated and evaluated (the default pool if nil)
+
+	Decay DecayPolicy `json:"-"` // the rule by which particles' root nodes die after each evaluation (the quadratic rule if nil)
+
+	Injections []Injection // the injections between particles applied during the most recent tick
+
+	SnapshotPath     string `json:"-"` // the file to which the macrocosm is periodically snapshotted (disabled if empty)
+	SnapshotInterval int64  `json:"-"` // the number of ticks between each of the macrocosm's snapshots
+
 	Lock sync.RWMutex `graphql:"-"` // the macrocosm's lock
 
+	traced map[Vector]bool          // the particles whose evaluations are traced
+	traces map[Vector]ParticleTrace // the most recent traces of the traced particles
+
 	logger loggo.Logger `graphql:"-"` // the macrocosm's logger
 }
 
 /* BEGIN EXPORTED METHODS */
 
 // NewMacrocosm initializes a new macrocosm with an empty set of particles.
-func NewMacrocosm() Macrocosm {
+// Two macrocosms initialized with the same seed will produce the same
+// universe.
+func NewMacrocosm(seed int64) Macrocosm {
 	return Macrocosm{
-		Particles: make(map[Vector]particle.Particle), // Set the macrocosm's particle set to an empty  map of particles
+		Particles:     NewChunkedStore(DefaultChunkSize), // Set the macrocosm's particle set to an empty store of particles
+		Seed:          seed,                              // Set the macrocosm's seed
+		Operations:    activation.NewOperationRegistry(), // Give the macrocosm its own set of operations
+		LinkMortality: activation.DefaultLinkMortality,   // Use the default link mortality
 	} // Return the initialized macrocosm
 }
 
 // Start starts the simulation in a blocking manner.
 func (macrocosm *Macrocosm) Start() {
+	macrocosm.StartContext(context.Background()) // Start the simulation
+}
+
+// StartContext starts the simulation in a blocking manner, until the given
+// context is cancelled. Returns the context's error.
+func (macrocosm *Macrocosm) StartContext(ctx context.Context) error {
 	for {
-		macrocosm.Expand() // Expand the macrocosm
-		macrocosm.Poll()   // Poll the macrocosm
+		err := macrocosm.ExpandContext(ctx) // Expand the macrocosm
+		if err != nil {                     // Check for errors
+			return err // Return the error
+		}
+
+		err = macrocosm.PollContext(ctx) // Poll the macrocosm
+		if err != nil {                  // Check for errors
+			return err // Return the error
+		}
+
+		err = macrocosm.Checkpoint() // Snapshot the macrocosm, if due
+		if err != nil {              // Check for errors
+			macrocosm.logger.Errorf("failed to snapshot macrocosm: %v", err) // Log the error
+		}
 	}
 }
 
@@ -66,7 +136,7 @@ func (macrocosm *Macrocosm) FlattenParticles() (particles [][][]particle.Particl
 			// Iterate through the possible x coordinates in the macrocosm
 			for x := macrocosm.Head[0].X; x >= macrocosm.Head[1].X; x-- {
 				// Get a vector for the current 3d position, add the particle from the macrocosm into the flattened particle slice leaf
-				particles[int(math.Abs(float64(z+macrocosm.Head[1].Z)))][int(math.Abs(float64(y+macrocosm.Head[1].Y)))] = append(particles[int(math.Abs(float64(z+macrocosm.Head[1].Z)))][int(math.Abs(float64(y+macrocosm.Head[1].Y)))], macrocosm.Particles[NewVector(x, y, z)])
+				particles[int(math.Abs(float64(z+macrocosm.Head[1].Z)))][int(math.Abs(float64(y+macrocosm.Head[1].Y)))] = append(particles[int(math.Abs(float64(z+macrocosm.Head[1].Z)))][int(math.Abs(float64(y+macrocosm.Head[1].Y)))], macrocosm.particleAt(NewVector(x, y, z)))
 			}
 		}
 	}
@@ -76,20 +146,42 @@ func (macrocosm *Macrocosm) FlattenParticles() (particles [][][]particle.Particl
 
 // HasParticle checks that a particle exists at the given vector, vec.
 func (macrocosm *Macrocosm) HasParticle(vec Vector) (particle.Particle, bool) {
-	macrocosm.Lock.Lock() // Lock the macrocosm
-
-	particle, ok := macrocosm.Particles[vec] // Get an existence signal from the particles map
-
-	macrocosm.Lock.Unlock() // Unlock the macrocosm
-
-	return particle, ok // Return whether or not the particle exists
+	return macrocosm.Particles.Get(vec) // Return whether or not the particle exists
 }
 
-// Poll executes the current frame of the macrocosm.
+// Poll executes the current frame of the macrocosm. Under synchronous
+// updates, each particle reads the values of its neighbors at the current
+// tick, and writes its own state into a buffer holding the next tick, which
+// replaces the current state once every particle has been evaluated. The
+// liveness of the nodes and links of a particle's net (changed by decay,
+// killing, and link mortality) is updated in place, however, so the nets in
+// the store may already hold the next tick's liveness before the buffer is
+// swapped in. Since a particle's net is only consulted when evaluating the
+// particle itself, this doesn't affect the outcome of the tick.
 func (macrocosm *Macrocosm) Poll() {
+	macrocosm.PollContext(context.Background()) // Poll the macrocosm
+}
+
+// PollContext executes the current frame of the macrocosm, unless the given
+// context is cancelled first. Once cancelled, no further particles are
+// evaluated, and the tick isn't advanced. A cancelled poll doesn't leave the
+// macrocosm as it was before the poll, however: the nodes and links of each
+// particle's net are shared with the particle in the store, so the liveness
+// changes made while evaluating the particles that were already evaluated
+// (by decay, killing, and link mortality) are kept. Under synchronous
+// updates, the new values of those particles are discarded; under
+// asynchronous updates, they may already have been written back. Returns the
+// context's error.
+func (macrocosm *Macrocosm) PollContext(ctx context.Context) error {
 	macrocosm.logger.Infof("polling...") // Log the pending evaluation
 
-	DoForVectorsBetween(macrocosm.Head[0], macrocosm.Head[1], func(vec Vector) {
+	var injections []Injection      // Get a slice to store the injections made by each of the particles in
+	injectionsMutex := sync.Mutex{} // Get a synchronization lock for the injections slice
+
+	next := make(map[Vector]particle.Particle) // Get a buffer to store the state of each of the particles at the next tick in
+	nextMutex := sync.Mutex{}                  // Get a synchronization lock for the next tick's buffer
+
+	err := macrocosm.forVectorsBetween(ctx, macrocosm.Head[0], macrocosm.Head[1], func(vec Vector) {
 		particle, ok := macrocosm.HasParticle(vec) // Get the particle at the given vector
 
 		// Check no particle at the vector
@@ -104,30 +196,53 @@ func (macrocosm *Macrocosm) Poll() {
 
 		i := particle.NumAliveNodes() // Get the number of alive nodes for the particle
 
-		a, b := vec.CornersAtParamCount(i) // Get the corners at the given number of parameters
+		var neighbors []neighbor // Get a slice to store the particle's neighbors in
 
-		var params []activation.Parameter // Get a slice to store the particle's execution parameters in
-		paramsMutex := sync.Mutex{}       // Get a synchronization lock for the params slice
+		// Iterate through the particle's neighborhood, in order, so that each neighbor is always passed to the same root node
+		for _, offset := range macrocosm.neighborhood().Offsets(i) {
+			pVec := vec.Add(offset) // Get the location of the neighbor
 
-		DoForVectorsBetween(a, b, func(pVec Vector) {
 			pParticle, ok := macrocosm.HasParticle(pVec) // Get the particle at the given vector
 
 			// Check no particles at vector
 			if !ok {
-				return // Stop execution
+				continue // Continue
 			}
 
-			paramsMutex.Lock() // Lock the params slice
+			neighbors = append(neighbors, neighbor{vec: pVec, param: pParticle.Value}) // Add the neighbor to the neighbors slice
+		}
+
+		params := make([]activation.Parameter, len(neighbors)) // Get a slice to store the particle's execution parameters in
+
+		// Iterate through the neighbors
+		for j := range neighbors {
+			macrocosm.stageInjectionTarget(vec, &neighbors[j]) // Let the particle inject into the neighbor
+
+			params[j] = neighbors[j].param // Add a parameter to the parameters slice
+		}
+
+		env := macrocosm.environmentAt(vec, pollingStage) // Get the environment in which the particle will be evaluated
+
+		// Check the particle is traced
+		if macrocosm.isTraced(vec) {
+			env.Tracer = activation.NewTracer() // Trace the particle's evaluation
+		}
 
-			params = append(params, pParticle.Value) // Add a parameter to the parameters slice
+		output := releaseInjectionTargets(particle.Output(env, params...), neighbors) // Evaluate the particle
 
-			paramsMutex.Unlock() // Unlock the params slice
-		}) // For each of the surrounding particles, check that
+		particle.Value = output       // Set the particle's value to the particle's output
+		particle.Steps = env.Gas.Used // Set the particle's operating complexity to the number of steps taken
+		particle.Age++                // Age the particle
 
-		output := particle.Net.Output(params...) // Evaluate the particle
+		macrocosm.decayPolicy().Decay(env, &particle) // DIE
 
-		particle.Value = output   // Set the particle's value to the particle's output
-		particle.Net.ApplyDecay() // DIE
+		particle.Net = mutation.Mutate(env, particle.Net, macrocosm.Mutation)         // Mutate the particle's net
+		particle.Complexity = analysis.Complexity(macrocosm.Operations, particle.Net) // Measure the particle's functional complexity
+
+		// Check the evaluation was cut off
+		if particle.Value.IsError() && particle.Value.A.Error == activation.ErrOutOfGas {
+			macrocosm.logger.Debugf("particle at vector {%d, %d, %d} ran out of gas after %d steps", vec.X, vec.Y, vec.Z, particle.Steps) // Log the cut off evaluation
+		}
 
 		// Check no state changes
 		if particle.Value.IsZero() {
@@ -135,20 +250,73 @@ func (macrocosm *Macrocosm) Poll() {
 
 			macrocosm.logger.Debugf("particle at vector {%d, %d, %d} effectively dead; killing", vec.X, vec.Y, vec.Z) // Log the pending termination
 		} else {
-			macrocosm.logger.Debugf("particle at vector {%d, %d, %d} evaluated successfully (%d inputs): {i: %d, b: %v, a: %+v}", vec.X, vec.Y, vec.Z, i, particle.Value.I, particle.Value.B, particle.Value.A) // Log the successful evaluation
+			macrocosm.logger.Debugf("particle at vector {%d, %d, %d} evaluated successfully (%d inputs, %d steps, complexity %d): {i: %d, b: %v, a: %+v}", vec.X, vec.Y, vec.Z, i, particle.Steps, particle.Complexity, particle.Value.I, particle.Value.B, particle.Value.A) // Log the successful evaluation
+		}
+
+		// Check the particle was traced
+		if env.Tracer != nil {
+			macrocosm.recordTrace(ParticleTrace{
+				Tick:   macrocosm.Tick,     // Set the tick of the evaluation
+				Vector: vec,                // Set the location of the particle
+				Inputs: params,             // Set the particle's inputs
+				Steps:  env.Tracer.Steps(), // Set the steps taken by the evaluation
+				Output: output,             // Set the output of the evaluation
+				Killed: !particle.Alive(),  // Set whether or not the particle was killed
+			}) // Record the trace
+		}
+
+		// Iterate through the neighbors
+		for _, n := range neighbors {
+			// Check the particle injected into the neighbor
+			if injection, ok := macrocosm.collectInjection(vec, n, output); ok {
+				injectionsMutex.Lock() // Lock the injections slice
+
+				injections = append(injections, injection) // Add the injection to the injections slice
+
+				injectionsMutex.Unlock() // Unlock the injections slice
+			}
+		}
+
+		// Check the particle's neighbors may see its new state during this tick
+		if macrocosm.Update == AsynchronousUpdate {
+			macrocosm.Particles.Put(vec, particle) // Put the particle back in the macrocosm
+
+			return // Stop execution
 		}
 
-		macrocosm.Lock.Lock() // Lock the macrocosm
+		nextMutex.Lock() // Lock the next tick's buffer
 
-		macrocosm.Particles[vec] = particle // Put the particle back in the macrocosm
+		next[vec] = particle // Buffer the particle's new state
 
-		macrocosm.Lock.Unlock() // Lock the macrocosm
+		nextMutex.Unlock() // Unlock the next tick's buffer
 	}) // For each of the particles in the macrocosm, poll it
+	if err != nil { // Check for errors
+		return err // Return the error, discarding the buffered particles
+	}
+
+	// Iterate through the buffered particles
+	for vec, particle := range next {
+		macrocosm.Particles.Put(vec, particle) // Swap the particle's new state in
+	}
+
+	macrocosm.Injections = macrocosm.applyInjections(injections) // Apply the injections made by each of the particles
+
+	macrocosm.Tick++ // Increment the number of elapsed ticks
+
+	return nil // No error occurred, return nil
 }
 
 // Expand generates a new round of particles, and attaches them to the existing
 // macrocosm as an "outer shell."
 func (macrocosm *Macrocosm) Expand() {
+	macrocosm.ExpandContext(context.Background()) // Expand the macrocosm
+}
+
+// ExpandContext generates a new round of particles, unless the given context
+// is cancelled first. A cancelled expansion leaves the head and shell as they
+// were, and the particles it didn't generate are generated by the next
+// expansion. Returns the context's error.
+func (macrocosm *Macrocosm) ExpandContext(ctx context.Context) error {
 	// Check the logger is not enabled
 	if !macrocosm.logger.IsInfoEnabled() {
 		macrocosm.logger = loggo.GetLogger(fmt.Sprintf("macrocosm_%d", macrocosm.Identifier)) // Set the logger of the macrocosm
@@ -160,34 +328,73 @@ func (macrocosm *Macrocosm) Expand() {
 	if _, ok := macrocosm.HasParticle(Zero()); !ok {
 		loc := Zero() // Get the location of the root particle
 
-		macrocosm.Particles[loc] = particle.RandomParticle()             // Set the root particle to a random particle
+		macrocosm.Particles.Put(loc, macrocosm.randomParticleAt(loc))    // Set the root particle to a random particle
 		macrocosm.Head = [2]Vector{loc, loc}                             // Set the head to the location
 		macrocosm.Shell = [2]Vector{loc.Corner(true), loc.Corner(false)} // Set the head to the location's corners
 
 		macrocosm.logger.Debugf("root layer initialized successfully") // Log the successful expansion
 
-		return // Stop execution
+		return nil // Stop execution
 	}
 
 	upperCorner, lowerCorner := macrocosm.Shell[0], macrocosm.Shell[1] // Get the macrocosm's shell corners
 
 	macrocosm.logger.Infof("expanding to layer %d", upperCorner.Z) // Log the pending expansion
 
-	DoForVectorsBetween(upperCorner, lowerCorner, func(vec Vector) {
+	err := macrocosm.forVectorsBetween(ctx, upperCorner, lowerCorner, func(vec Vector) {
 		// Check a particle doesn't exist at the vector
 		if _, ok := macrocosm.HasParticle(vec); !ok {
-			rand := particle.RandomParticle() // Generate a random particle
-
-			macrocosm.Lock.Lock() // Lock the macrocosm
+			rand := macrocosm.randomParticleAt(vec) // Generate a random particle
 
-			macrocosm.Particles[vec] = rand // Set the particle to a random particle
-
-			macrocosm.Lock.Unlock() // Unlock the macrocosm
+			macrocosm.Particles.Put(vec, rand) // Set the particle to a random particle
 		}
 	}) // Make each of the enclosing particles
+	if err != nil { // Check for errors
+		return err // Return the error
+	}
 
 	macrocosm.Head = macrocosm.Shell                                                               // Set the head of the macrocosm to its old shell
 	macrocosm.Shell = [2]Vector{macrocosm.Shell[0].Corner(true), macrocosm.Shell[1].Corner(false)} // Expand the macrocosm's head
+
+	return nil // No error occurred, return nil
+}
+
+// MeanComplexity gets the mean functional complexity of the live particles in
+// the macrocosm, or zero if none of the particles are alive.
+func (macrocosm *Macrocosm) MeanComplexity() float64 {
+	sum, n := 0, 0 // Get buffers to store the total complexity and the number of live particles in
+
+	macrocosm.Particles.Range(func(vec Vector, particle particle.Particle) bool {
+		// Check the particle is alive
+		if particle.Alive() {
+			sum += particle.Complexity // Add the particle's complexity
+			n++                        // Count the particle
+		}
+
+		return true // Visit the next particle
+	}) // Iterate through the macrocosm's particles
+
+	// Check no particles are alive
+	if n == 0 {
+		return 0 // Return a zero mean
+	}
+
+	return float64(sum) / float64(n) // Return the mean complexity
+}
+
+// ParticleFrame captures the current particle state of the macrocosm.
+func (macrocosm *Macrocosm) ParticleFrame() ParticleFrame {
+	particles := make(map[Vector]particle.Particle, macrocosm.Particles.Len()) // Get a buffer to copy the particles into
+
+	macrocosm.Particles.Range(func(vec Vector, p particle.Particle) bool {
+		p.Net = p.Net.Clone() // Copy the particle's net, so that the frame isn't changed by later ticks
+
+		particles[vec] = p // Add the particle
+
+		return true // Visit the next particle
+	}) // Iterate through the macrocosm's particles
+
+	return ParticleFrame{Tick: macrocosm.Tick, Particles: particles} // Return the frame
 }
 
 // Dereference copies the value from the given macrocosm reference.
@@ -201,3 +408,75 @@ func Dereference(macrocosm *Macrocosm) FlattenedMacrocosm {
 }
 
 /* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// randomParticleAt generates a new random particle for the given vector.
+func (macrocosm *Macrocosm) randomParticleAt(vec Vector) particle.Particle {
+	return particle.RandomParticle(macrocosm.environmentAt(vec, expansionStage), func(p particle.Particle) particle.Particle {
+		p.Net.Reducer = macrocosm.Reducer                               // Use the macrocosm's reducer
+		p.Net.LinkMode = macrocosm.LinkMode                             // Use the macrocosm's link mode
+		p.Net.Combiner = macrocosm.Combiner                             // Use the macrocosm's combiner
+		p.Complexity = analysis.Complexity(macrocosm.Operations, p.Net) // Measure the particle's functional complexity
+
+		return p // Return the final particle
+	}) // Return the generated particle
+}
+
+// forVectorsBetween runs the given callback for each of the vectors between
+// points a (inclusive) and b (inclusive) in the macrocosm's execution mode, on
+// the macrocosm's pool.
+func (macrocosm *Macrocosm) forVectorsBetween(ctx context.Context, a, b Vector, callback func(vec Vector)) error {
+	return ForVectorsBetween(ctx, macrocosm.Execution, macrocosm.Pool, a, b, callback) // Run the callbacks
+}
+
+// particleAt gets the particle at the given vector, or an empty particle if
+// there is none.
+func (macrocosm *Macrocosm) particleAt(vec Vector) particle.Particle {
+	p, _ := macrocosm.Particles.Get(vec) // Get the particle
+
+	return p // Return the particle
+}
+
+// neighborhood gets the macrocosm's neighborhood, defaulting to the adaptive
+// neighborhood.
+func (macrocosm *Macrocosm) neighborhood() Neighborhood {
+	// Check the macrocosm has no neighborhood
+	if macrocosm.Neighborhood == nil {
+		return AdaptiveNeighborhood{} // Use the adaptive neighborhood
+	}
+
+	return macrocosm.Neighborhood // Return the macrocosm's neighborhood
+}
+
+// decayPolicy gets the macrocosm's decay policy, defaulting to the quadratic
+// rule.
+func (macrocosm *Macrocosm) decayPolicy() DecayPolicy {
+	// Check the macrocosm has no decay policy
+	if macrocosm.Decay == nil {
+		return QuadraticDecay{} // Use the quadratic rule
+	}
+
+	return macrocosm.Decay // Return the macrocosm's decay policy
+}
+
+// environmentAt derives an environment for the particle at the given vector
+// from the macrocosm's seed, the current tick, and the given stage. Since the
+// derived environment doesn't depend on the order in which particles are
+// visited, concurrent evaluations remain reproducible.
+func (macrocosm *Macrocosm) environmentAt(vec Vector, s stage) *activation.Environment {
+	hash := fnv.New64a() // Get a hash to mix the environment's inputs with
+
+	// Write each of the inputs to the hash
+	for _, v := range []int64{macrocosm.Seed, macrocosm.Tick, int64(s), vec.X, vec.Y, vec.Z} {
+		binary.Write(hash, binary.BigEndian, v) // Write the input to the hash
+	}
+
+	env := activation.NewEnvironment(int64(hash.Sum64())) // Derive the environment from the hash
+	env.Operations = macrocosm.Operations                 // Use the macrocosm's operations
+	env.LinkMortality = macrocosm.LinkMortality           // Use the macrocosm's link mortality
+
+	return env // Return the derived environment
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,251 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultNeighborhoodRadius is the default radius of the Moore, von Neumann,
+// and radius neighborhoods.
+const DefaultNeighborhoodRadius = 1
+
+var (
+	// ErrUnknownNeighborhood is an error definition describing a
+	// neighborhood name that doesn't correspond to any neighborhood.
+	ErrUnknownNeighborhood = errors.New("unknown neighborhood")
+
+	// ErrNoOffsets is an error definition describing an offsets
+	// neighborhood without any offsets, in which particles would be given no
+	// inputs.
+	ErrNoOffsets = errors.New("offsets neighborhood must have at least one offset")
+
+	// ErrInvalidOffset is an error definition describing an offset that isn't
+	// of the form x,y,z.
+	ErrInvalidOffset = errors.New("offset must be of the form x,y,z")
+)
+
+// Neighborhood is a rule determining the locations from which a particle
+// takes its inputs, relative to the particle itself. The particles at each of
+// the locations are passed to the particle's root nodes in order; locations
+// without particles are skipped.
+type Neighborhood interface {
+	// Offsets gets the offsets of the locations from which a particle with
+	// the given number of live root nodes takes its inputs, in the order in
+	// which the inputs are passed to its root nodes.
+	Offsets(numAliveNodes int) []Vector
+
+	// String gets the textual representation of the neighborhood.
+	String() string
+}
+
+// AdaptiveNeighborhood is a neighborhood consisting of the smallest cube
+// around the particle (including the particle itself) holding at least as
+// many locations as the particle has live root nodes.
+type AdaptiveNeighborhood struct{}
+
+// MooreNeighborhood is a neighborhood consisting of each of the locations
+// within the given Chebyshev distance of the particle (i.e. the surrounding
+// cube), excluding the particle itself.
+type MooreNeighborhood struct {
+	Radius int // the size of the neighborhood (DefaultNeighborhoodRadius if not positive)
+}
+
+// VonNeumannNeighborhood is a neighborhood consisting of each of the
+// locations within the given Manhattan distance of the particle, excluding the
+// particle itself.
+type VonNeumannNeighborhood struct {
+	Radius int // the size of the neighborhood (DefaultNeighborhoodRadius if not positive)
+}
+
+// RadiusNeighborhood is a neighborhood consisting of each of the locations
+// within the given Euclidean distance of the particle (i.e. the surrounding
+// sphere), excluding the particle itself.
+type RadiusNeighborhood struct {
+	Radius float64 // the size of the neighborhood (DefaultNeighborhoodRadius if not positive)
+}
+
+// OffsetNeighborhood is a neighborhood consisting of a user-specified list of
+// offsets. Inputs are passed to the particle's root nodes in the order of the
+// offsets.
+type OffsetNeighborhood struct {
+	List []Vector // the offsets of the locations in the neighborhood
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewNeighborhood initializes the neighborhood with the given name (adaptive,
+// moore, von-neumann, radius, or offsets). The given radius sets the size of
+// the Moore, von Neumann, and radius neighborhoods; if zero, the default
+// radius is used. The given offsets, of which there must be at least one, make
+// up the offsets neighborhood.
+func NewNeighborhood(name string, radius float64, offsets []Vector) (Neighborhood, error) {
+	// Handle the different neighborhoods
+	switch name {
+	case "adaptive":
+		return AdaptiveNeighborhood{}, nil // Return the adaptive neighborhood
+	case "moore":
+		return MooreNeighborhood{Radius: int(radius)}, nil // Return the Moore neighborhood
+	case "von-neumann":
+		return VonNeumannNeighborhood{Radius: int(radius)}, nil // Return the von Neumann neighborhood
+	case "radius":
+		return RadiusNeighborhood{Radius: radius}, nil // Return the radius neighborhood
+	case "offsets":
+		// Check the neighborhood has no offsets
+		if len(offsets) == 0 {
+			return nil, ErrNoOffsets // Return the error
+		}
+
+		return OffsetNeighborhood{List: offsets}, nil // Return the offsets neighborhood
+	default:
+		return nil, ErrUnknownNeighborhood // Return the error
+	}
+}
+
+// ParseOffset parses an offset of the form x,y,z.
+func ParseOffset(s string) (Vector, error) {
+	parts := strings.Split(s, ",") // Split the offset into its coordinates
+
+	// Check the offset doesn't have three coordinates
+	if len(parts) != 3 {
+		return Vector{}, ErrInvalidOffset // Return the error
+	}
+
+	var values []int64 // Get a buffer to store the parsed coordinates in
+
+	// Iterate through the coordinates
+	for _, part := range parts {
+		value, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64) // Parse the coordinate
+		if err != nil {                                                 // Check for errors
+			return Vector{}, ErrInvalidOffset // Return the error
+		}
+
+		values = append(values, value) // Add the coordinate
+	}
+
+	return NewVectorFromValues(values), nil // Return the offset
+}
+
+// Offsets gets the offsets of the smallest cube around the particle holding
+// at least the given number of locations, sorted by location.
+func (neighborhood AdaptiveNeighborhood) Offsets(numAliveNodes int) []Vector {
+	zero := Zero() // Get the location of the particle, relative to itself
+
+	a, b := zero.CornersAtParamCount(numAliveNodes) // Get the corners of the cube
+
+	return VectorsBetween(a.Lesser(b), a.Greater(b)) // Return the locations in the cube
+}
+
+// String gets the textual representation of the neighborhood.
+func (neighborhood AdaptiveNeighborhood) String() string {
+	return "adaptive" // Return the name of the neighborhood
+}
+
+// Offsets gets the offsets of the locations within the neighborhood's
+// Chebyshev distance, sorted by location.
+func (neighborhood MooreNeighborhood) Offsets(numAliveNodes int) []Vector {
+	r := int64(radiusOrDefault(float64(neighborhood.Radius))) // Get the radius of the neighborhood
+
+	return offsetsWithin(r, func(offset Vector) bool {
+		return true // Every location in the cube is in the neighborhood
+	}) // Return the offsets
+}
+
+// String gets the textual representation of the neighborhood.
+func (neighborhood MooreNeighborhood) String() string {
+	return fmt.Sprintf("moore (radius %d)", int(radiusOrDefault(float64(neighborhood.Radius)))) // Return the name of the neighborhood
+}
+
+// Offsets gets the offsets of the locations within the neighborhood's
+// Manhattan distance, sorted by location.
+func (neighborhood VonNeumannNeighborhood) Offsets(numAliveNodes int) []Vector {
+	r := int64(radiusOrDefault(float64(neighborhood.Radius))) // Get the radius of the neighborhood
+
+	return offsetsWithin(r, func(offset Vector) bool {
+		return abs(offset.X)+abs(offset.Y)+abs(offset.Z) <= r // Check the location is within the Manhattan distance
+	}) // Return the offsets
+}
+
+// String gets the textual representation of the neighborhood.
+func (neighborhood VonNeumannNeighborhood) String() string {
+	return fmt.Sprintf("von-neumann (radius %d)", int(radiusOrDefault(float64(neighborhood.Radius)))) // Return the name of the neighborhood
+}
+
+// Offsets gets the offsets of the locations within the neighborhood's
+// Euclidean distance, sorted by location.
+func (neighborhood RadiusNeighborhood) Offsets(numAliveNodes int) []Vector {
+	r := radiusOrDefault(neighborhood.Radius) // Get the radius of the neighborhood
+
+	return offsetsWithin(int64(r), func(offset Vector) bool {
+		return float64(offset.X*offset.X+offset.Y*offset.Y+offset.Z*offset.Z) <= r*r // Check the location is within the Euclidean distance
+	}) // Return the offsets
+}
+
+// String gets the textual representation of the neighborhood.
+func (neighborhood RadiusNeighborhood) String() string {
+	return fmt.Sprintf("radius (radius %g)", radiusOrDefault(neighborhood.Radius)) // Return the name of the neighborhood
+}
+
+// Offsets gets the neighborhood's offsets, in the order in which they were
+// specified.
+func (neighborhood OffsetNeighborhood) Offsets(numAliveNodes int) []Vector {
+	return neighborhood.List // Return the offsets
+}
+
+// String gets the textual representation of the neighborhood.
+func (neighborhood OffsetNeighborhood) String() string {
+	var offsets []string // Get a buffer to store the formatted offsets in
+
+	// Iterate through the offsets
+	for _, offset := range neighborhood.List {
+		offsets = append(offsets, fmt.Sprintf("%d,%d,%d", offset.X, offset.Y, offset.Z)) // Format the offset
+	}
+
+	return fmt.Sprintf("offsets (%s)", strings.Join(offsets, " ")) // Return the name of the neighborhood
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// offsetsWithin gets the offsets in the cube with the given radius around the
+// origin, excluding the origin itself, that satisfy the given predicate,
+// sorted by location (since VectorsBetween visits the cube in that order).
+func offsetsWithin(r int64, include func(offset Vector) bool) []Vector {
+	var offsets []Vector // Get a buffer to store the offsets in
+
+	// Iterate through the offsets in the cube
+	for _, offset := range VectorsBetween(NewVector(-r, -r, -r), NewVector(r, r, r)) {
+		// Check the offset is in the neighborhood
+		if !offset.IsZero() && include(offset) {
+			offsets = append(offsets, offset) // Add the offset
+		}
+	}
+
+	return offsets // Return the offsets
+}
+
+// radiusOrDefault gets the given radius, or the default radius if the given
+// radius isn't positive.
+func radiusOrDefault(r float64) float64 {
+	// Check the radius isn't positive
+	if r <= 0 {
+		return DefaultNeighborhoodRadius // Use the default radius
+	}
+
+	return r // Return the radius
+}
+
+// abs gets the absolute value of the given integer.
+func abs(x int64) int64 {
+	// Check the integer is negative
+	if x < 0 {
+		return -x // Negate the integer
+	}
+
+	return x // Return the integer
+}
+
+/* END INTERNAL METHODS */
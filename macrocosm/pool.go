@@ -0,0 +1,218 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrUnknownExecutionMode is an error definition describing an execution
+// mode name that doesn't correspond to any execution mode.
+var ErrUnknownExecutionMode = errors.New("unknown execution mode")
+
+// ExecutionMode represents the way in which the callbacks of an iteration
+// over vectors are run.
+type ExecutionMode int
+
+const (
+	// PooledExecution is an execution mode in which the callback for each
+	// vector is run as a separate task on a worker pool.
+	PooledExecution ExecutionMode = iota
+
+	// ChunkedExecution is an execution mode in which the vectors are split
+	// into one contiguous chunk per worker, and each chunk is run as a single
+	// task on a worker pool, visiting its vectors one after another.
+	ChunkedExecution
+
+	// SequentialExecution is an execution mode in which each of the callbacks
+	// is run in the calling goroutine, one after another.
+	SequentialExecution
+)
+
+// executionModeNames maps each execution mode to its textual representation.
+var executionModeNames = map[ExecutionMode]string{
+	PooledExecution:     "pooled",
+	ChunkedExecution:    "chunked",
+	SequentialExecution: "sequential",
+}
+
+// WorkerPool is a fixed set of goroutines that run submitted tasks. A pool is
+// reusable, and may be shared between any number of iterations and
+// macrocosms.
+type WorkerPool struct {
+	tasks chan func() // the channel through which tasks are handed to idle workers
+
+	size int // the number of workers in the pool
+}
+
+var (
+	// defaultPool is the pool used by iterations that aren't given a pool.
+	defaultPool *WorkerPool
+
+	// defaultPoolOnce initializes the default pool.
+	defaultPoolOnce sync.Once
+)
+
+/* BEGIN EXPORTED METHODS */
+
+// ParseExecutionMode gets the execution mode with the given name.
+func ParseExecutionMode(name string) (ExecutionMode, error) {
+	// Iterate through the named execution modes
+	for mode, modeName := range executionModeNames {
+		// Check the names match
+		if modeName == name {
+			return mode, nil // Return the execution mode
+		}
+	}
+
+	return PooledExecution, ErrUnknownExecutionMode // Return the error
+}
+
+// String gets the textual representation of the execution mode.
+func (mode ExecutionMode) String() string {
+	return executionModeNames[mode] // Return the name of the execution mode
+}
+
+// NewWorkerPool initializes a new pool with the given number of workers. If
+// the number isn't positive, a worker is started for each CPU.
+func NewWorkerPool(size int) *WorkerPool {
+	// Check the size isn't positive
+	if size <= 0 {
+		size = runtime.NumCPU() // Start a worker for each CPU
+	}
+
+	pool := &WorkerPool{
+		tasks: make(chan func()), // Make the task channel
+		size:  size,              // Set the number of workers
+	} // Initialize the pool
+
+	// Start each of the workers
+	for i := 0; i < size; i++ {
+		go pool.work() // Start the worker
+	}
+
+	return pool // Return the initialized pool
+}
+
+// DefaultWorkerPool gets the pool shared by iterations that aren't given a
+// pool, which has a worker for each CPU.
+func DefaultWorkerPool() *WorkerPool {
+	defaultPoolOnce.Do(func() {
+		defaultPool = NewWorkerPool(0) // Initialize the default pool
+	}) // Initialize the default pool, if it hasn't been initialized yet
+
+	return defaultPool // Return the default pool
+}
+
+// Size gets the number of workers in the pool.
+func (pool *WorkerPool) Size() int {
+	return pool.size // Return the number of workers
+}
+
+// Submit runs the given task on an idle worker. If every worker is busy, the
+// task is run in the calling goroutine instead, such that tasks may submit
+// further tasks to the same pool without deadlocking.
+func (pool *WorkerPool) Submit(task func()) {
+	select {
+	case pool.tasks <- task: // Hand the task to an idle worker
+	default:
+		task() // Run the task in the calling goroutine
+	}
+}
+
+// Close stops each of the pool's workers once they finish their current
+// tasks. The pool must not be used after it has been closed.
+func (pool *WorkerPool) Close() {
+	close(pool.tasks) // Stop the workers
+}
+
+// ForVectorsBetween runs the given callback for each of the vectors between
+// points a (inclusive) and b (inclusive) in the given execution mode, using
+// the given pool (or the default pool if nil). Once the given context is
+// cancelled, no further callbacks are started; the callbacks already started
+// are waited for, and the context's error is returned.
+func ForVectorsBetween(ctx context.Context, mode ExecutionMode, pool *WorkerPool, a, b Vector, callback func(vec Vector)) error {
+	lower, upper := a.Lesser(b), a.Greater(b) // Get the lowest and highest corners of the box between the points
+
+	dims := upper.Sub(lower)            // Get the distance between the corners
+	dims = dims.Add(NewVector(1, 1, 1)) // Include both of the corners
+
+	n := dims.Product() // Get the number of vectors in the box
+
+	// Check the callbacks should be run in the calling goroutine
+	if mode == SequentialExecution {
+		// Iterate through the vectors
+		for i := int64(0); i < n; i++ {
+			// Check the iteration has been cancelled
+			if ctx.Err() != nil {
+				return ctx.Err() // Stop iterating
+			}
+
+			callback(vectorAt(lower, dims, i)) // Run the callback with the vector
+		}
+
+		return nil // No error occurred, return nil
+	}
+
+	// Check no pool was provided
+	if pool == nil {
+		pool = DefaultWorkerPool() // Use the default pool
+	}
+
+	chunk := int64(1) // Get the number of vectors visited by each task
+
+	// Check each worker should visit a contiguous chunk of vectors
+	if mode == ChunkedExecution {
+		chunk = (n + int64(pool.Size()) - 1) / int64(pool.Size()) // Split the vectors evenly between the workers
+	}
+
+	var wg sync.WaitGroup // Get a wait group
+
+	// Iterate through the chunks
+	for start := int64(0); start < n && ctx.Err() == nil; start += chunk {
+		start, end := start, start+chunk // Get the bounds of the chunk
+
+		// Check the chunk runs past the last vector
+		if end > n {
+			end = n // Stop at the last vector
+		}
+
+		wg.Add(1) // Wait for the chunk
+
+		pool.Submit(func() {
+			defer wg.Done() // Finish the chunk once done
+
+			// Iterate through the chunk's vectors
+			for i := start; i < end && ctx.Err() == nil; i++ {
+				callback(vectorAt(lower, dims, i)) // Run the callback with the vector
+			}
+		}) // Visit the chunk
+	}
+
+	wg.Wait() // Wait for all of the callbacks to terminate
+
+	return ctx.Err() // Return any error
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// work runs the tasks handed to the worker until the pool is closed.
+func (pool *WorkerPool) work() {
+	// Run each of the tasks handed to the worker
+	for task := range pool.tasks {
+		task() // Run the task
+	}
+}
+
+// vectorAt gets the vector at the given index in the box with the given
+// lowest corner and dimensions, where vectors are ordered by their z values,
+// then their y values, and then their x values.
+func vectorAt(lower, dims Vector, i int64) Vector {
+	return NewVector(lower.X+i%dims.X, lower.Y+i/dims.X%dims.Y, lower.Z+i/(dims.X*dims.Y)) // Return the vector
+}
+
+/* END INTERNAL METHODS */
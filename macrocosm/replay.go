@@ -0,0 +1,128 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrNoParticleFrames is an error definition describing a replay of a
+	// history in which no particle frames were recorded.
+	ErrNoParticleFrames = errors.New("no particle frames have been recorded")
+
+	// ErrEndOfReplay is an error definition describing an attempt to step
+	// past the first or last recorded particle frame.
+	ErrEndOfReplay = errors.New("no more particle frames to replay")
+
+	// ErrTickNotRecorded is an error definition describing a tick preceding
+	// each of the recorded particle frames.
+	ErrTickNotRecorded = errors.New("no particle frame recorded at or before tick")
+)
+
+// FrameStore is a store of the particle frames recorded throughout a
+// macrocosm's history.
+type FrameStore interface {
+	// Ticks gets the ticks at which each of the particle frames in the store
+	// were recorded.
+	Ticks() ([]int64, error)
+
+	// ParticleFrame gets the particle frame recorded at the given tick.
+	ParticleFrame(tick int64) (*ParticleFrame, error)
+}
+
+// Replay steps backwards and forwards through the particle frames recorded in
+// a frame store. Replays aren't safe for concurrent use.
+type Replay struct {
+	store FrameStore // the store holding the recorded frames
+
+	ticks []int64 // the ticks at which each of the frames were recorded, in ascending order
+
+	position int // the index of the current frame's tick
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewReplay initializes a new replay of the particle frames in the given
+// store, positioned at the first recorded frame.
+func NewReplay(store FrameStore) (*Replay, error) {
+	ticks, err := store.Ticks() // Get the recorded ticks
+	if err != nil {             // Check for errors
+		return nil, err // Return the error
+	}
+
+	// Check no frames have been recorded
+	if len(ticks) == 0 {
+		return nil, ErrNoParticleFrames // Return the error
+	}
+
+	// Sort the ticks, in case the store doesn't
+	sort.Slice(ticks, func(i, j int) bool {
+		return ticks[i] < ticks[j] // Sort by tick
+	})
+
+	return &Replay{
+		store: store, // Set the store
+		ticks: ticks, // Set the recorded ticks
+	}, nil // Return the initialized replay
+}
+
+// Ticks gets the ticks at which each of the replayed frames were recorded, in
+// ascending order.
+func (replay *Replay) Ticks() []int64 {
+	return replay.ticks // Return the ticks
+}
+
+// Tick gets the tick at which the current frame was recorded.
+func (replay *Replay) Tick() int64 {
+	return replay.ticks[replay.position] // Return the current tick
+}
+
+// Frame gets the current frame.
+func (replay *Replay) Frame() (*ParticleFrame, error) {
+	return replay.store.ParticleFrame(replay.Tick()) // Return the current frame
+}
+
+// Next steps forwards to the next recorded frame.
+func (replay *Replay) Next() (*ParticleFrame, error) {
+	// Check the current frame is the last
+	if replay.position == len(replay.ticks)-1 {
+		return nil, ErrEndOfReplay // Return the error
+	}
+
+	replay.position++ // Step forwards
+
+	return replay.Frame() // Return the next frame
+}
+
+// Previous steps backwards to the previous recorded frame.
+func (replay *Replay) Previous() (*ParticleFrame, error) {
+	// Check the current frame is the first
+	if replay.position == 0 {
+		return nil, ErrEndOfReplay // Return the error
+	}
+
+	replay.position-- // Step backwards
+
+	return replay.Frame() // Return the previous frame
+}
+
+// JumpTo moves to the most recent frame recorded at or before the given tick.
+// Since frames may be recorded only every few ticks, the returned frame's
+// tick may precede the given tick.
+func (replay *Replay) JumpTo(tick int64) (*ParticleFrame, error) {
+	i := sort.Search(len(replay.ticks), func(i int) bool {
+		return replay.ticks[i] > tick // Find the first frame recorded after the tick
+	}) // Get the index of the first frame recorded after the tick
+
+	// Check no frames were recorded at or before the tick
+	if i == 0 {
+		return nil, ErrTickNotRecorded // Return the error
+	}
+
+	replay.position = i - 1 // Move to the frame
+
+	return replay.Frame() // Return the frame
+}
+
+/* END EXPORTED METHODS */
@@ -0,0 +1,269 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/particle"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot.
+const SnapshotVersion = 1
+
+var (
+	// ErrNotASnapshot is an error definition describing a file that doesn't
+	// hold a macrocosm snapshot.
+	ErrNotASnapshot = errors.New("not a macrocosm snapshot")
+
+	// ErrUnsupportedSnapshotVersion is an error definition describing a
+	// snapshot written in a version of the format that can't be restored.
+	ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot version")
+)
+
+// snapshot is the full state of a macrocosm, as written by Snapshot.
+type snapshot struct {
+	Version int // the version of the snapshot format
+
+	Identifier int // the identifier of the macrocosm
+
+	Seed int64 // the seed from which all of the macrocosm's randomness is derived
+	Tick int64 // the number of times the macrocosm had been polled
+
+	Head  [2]Vector // the outermost non-nil particle
+	Shell [2]Vector // the outermost nil particle that should be created in the next round
+
+	Particles []particleRecord // each of the macrocosm's particles, sorted by location
+}
+
+// particleRecord is the serialized form of a single particle. Nets and values
+// are stored as genomes, such that nodes referenced by values, and whether or
+// not each node and link is alive, are preserved.
+type particleRecord struct {
+	Vector Vector // the location of the particle
+
+	Net   []byte // the particle's net, encoded as a genome
+	Value []byte // the value of the particle, encoded as a genome
+
+	Steps      int // the number of steps taken by the particle's most recent evaluation
+	Complexity int // the functional complexity of the particle's net
+	Age        int // the number of times the particle has been evaluated
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// Snapshot writes the full state of the macrocosm (i.e. each of its
+// particles, its head, shell, identifier, and tick, and its seed, from which
+// the state of its randomness is derived) to the given writer. The
+// macrocosm's configuration (e.g. its operations, decay policy, and mutation
+// rates) isn't included, and must be provided again when it is restored.
+// Since particles are updated without holding the macrocosm's lock, snapshots
+// must be taken between ticks (i.e. not concurrently with Poll or Expand) to
+// be consistent.
+func (macrocosm *Macrocosm) Snapshot(w io.Writer) error {
+	macrocosm.Lock.RLock() // Lock the macrocosm
+
+	s := snapshot{
+		Version:    SnapshotVersion,                                        // Set the version of the format
+		Identifier: macrocosm.Identifier,                                   // Set the identifier of the macrocosm
+		Seed:       macrocosm.Seed,                                         // Set the seed of the macrocosm
+		Tick:       macrocosm.Tick,                                         // Set the tick of the macrocosm
+		Head:       macrocosm.Head,                                         // Set the head of the macrocosm
+		Shell:      macrocosm.Shell,                                        // Set the shell of the macrocosm
+		Particles:  recordParticles(collectParticles(macrocosm.Particles)), // Record each of the particles
+	} // Capture the macrocosm's state
+
+	macrocosm.Lock.RUnlock() // Unlock the macrocosm
+
+	compressed := gzip.NewWriter(w) // Compress the snapshot
+
+	err := json.NewEncoder(compressed).Encode(s) // Write the snapshot
+	if err != nil {                              // Check for errors
+		return err // Return the error
+	}
+
+	return compressed.Close() // Flush the compressed snapshot
+}
+
+// Restore replaces the state of the macrocosm with the state written to the
+// given reader by Snapshot. The macrocosm's configuration is left untouched.
+// Since the macrocosm's randomness is derived from its seed and tick, a
+// restored macrocosm evolves exactly as the snapshotted macrocosm would have.
+func (macrocosm *Macrocosm) Restore(r io.Reader) error {
+	decompressed, err := gzip.NewReader(r) // Decompress the snapshot
+	if err == gzip.ErrHeader {             // Check the snapshot isn't compressed
+		return ErrNotASnapshot // Return the error
+	} else if err != nil { // Check for errors
+		return err // Return the error
+	}
+
+	var s snapshot // Get a buffer to read the snapshot into
+
+	err = json.NewDecoder(decompressed).Decode(&s) // Read the snapshot
+	if err != nil {                                // Check for errors
+		return err // Return the error
+	}
+
+	// Check the snapshot has no version
+	if s.Version == 0 {
+		return ErrNotASnapshot // Return the error
+	}
+
+	// Check the version is unsupported
+	if s.Version > SnapshotVersion {
+		return ErrUnsupportedSnapshotVersion // Return the error
+	}
+
+	particles, err := restoreParticles(s.Particles) // Restore each of the particles
+	if err != nil {                                 // Check for errors
+		return err // Return the error
+	}
+
+	macrocosm.Lock.Lock()         // Lock the macrocosm
+	defer macrocosm.Lock.Unlock() // Unlock the macrocosm once done
+
+	macrocosm.Particles.Clear() // Remove the macrocosm's particles
+
+	// Iterate through the restored particles
+	for vec, p := range particles {
+		macrocosm.Particles.Put(vec, p) // Put the particle in the macrocosm
+	}
+
+	macrocosm.Identifier = s.Identifier               // Set the identifier of the macrocosm
+	macrocosm.Seed, macrocosm.Tick = s.Seed, s.Tick   // Set the seed and tick of the macrocosm
+	macrocosm.Head, macrocosm.Shell = s.Head, s.Shell // Set the head and shell of the macrocosm
+	macrocosm.Injections = nil                        // Discard the injections made before the snapshot
+	macrocosm.traces = nil                            // Discard the traces recorded before the snapshot
+
+	return nil // No error occurred, return nil
+}
+
+// SnapshotFile writes a snapshot of the macrocosm to the file at the given
+// path. The snapshot is written to a temporary file first, and moved into
+// place once complete, such that an interrupted write never destroys the
+// previous snapshot.
+func (macrocosm *Macrocosm) SnapshotFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*") // Create the temporary file
+	if err != nil {                                                           // Check for errors
+		return err // Return the error
+	}
+
+	defer os.Remove(tmp.Name()) // Remove the temporary file, if it wasn't moved
+
+	err = macrocosm.Snapshot(tmp) // Write the snapshot
+	if err != nil {               // Check for errors
+		tmp.Close() // Close the temporary file
+
+		return err // Return the error
+	}
+
+	err = tmp.Close() // Close the temporary file
+	if err != nil {   // Check for errors
+		return err // Return the error
+	}
+
+	return os.Rename(tmp.Name(), path) // Move the snapshot into place
+}
+
+// RestoreFile restores the state of the macrocosm from the snapshot in the
+// file at the given path.
+func (macrocosm *Macrocosm) RestoreFile(path string) error {
+	f, err := os.Open(path) // Open the file
+	if err != nil {         // Check for errors
+		return err // Return the error
+	}
+
+	defer f.Close() // Close the file once done
+
+	return macrocosm.Restore(f) // Restore the snapshot
+}
+
+// Checkpoint snapshots the macrocosm to its snapshot path, if one is set and
+// the macrocosm's snapshot interval has elapsed.
+func (macrocosm *Macrocosm) Checkpoint() error {
+	// Check snapshots are disabled, or aren't due
+	if macrocosm.SnapshotPath == "" || macrocosm.SnapshotInterval <= 0 || macrocosm.Tick%macrocosm.SnapshotInterval != 0 {
+		return nil // Nothing to do
+	}
+
+	return macrocosm.SnapshotFile(macrocosm.SnapshotPath) // Snapshot the macrocosm
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// recordParticles serializes each of the given particles, sorted by location.
+func recordParticles(particles map[Vector]particle.Particle) []particleRecord {
+	records := make([]particleRecord, 0, len(particles)) // Get a buffer to store the records in
+
+	// Iterate through the particles
+	for vec, p := range particles {
+		records = append(records, particleRecord{
+			Vector:     vec,                                 // Set the location of the particle
+			Net:        activation.EncodeNet(p.Net),         // Encode the particle's net
+			Value:      activation.EncodeParameter(p.Value), // Encode the particle's value
+			Steps:      p.Steps,                             // Set the particle's steps
+			Complexity: p.Complexity,                        // Set the particle's complexity
+			Age:        p.Age,                               // Set the particle's age
+		}) // Record the particle
+	}
+
+	// Sort the records, so that the same particles are always written in the same order
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Vector.Less(records[j].Vector) // Sort by location
+	})
+
+	return records // Return the records
+}
+
+// collectParticles copies each of the particles in the given store into a
+// map.
+func collectParticles(store ParticleStore) map[Vector]particle.Particle {
+	particles := make(map[Vector]particle.Particle, store.Len()) // Get a buffer to copy the particles into
+
+	store.Range(func(vec Vector, p particle.Particle) bool {
+		particles[vec] = p // Copy the particle
+
+		return true // Visit the next particle
+	}) // Iterate through the store's particles
+
+	return particles // Return the particles
+}
+
+// restoreParticles deserializes each of the given particle records.
+func restoreParticles(records []particleRecord) (map[Vector]particle.Particle, error) {
+	particles := make(map[Vector]particle.Particle, len(records)) // Get a buffer to store the particles in
+
+	// Iterate through the records
+	for _, record := range records {
+		net, err := activation.DecodeNet(record.Net) // Decode the particle's net
+		if err != nil {                              // Check for errors
+			return nil, err // Return the error
+		}
+
+		value, err := activation.DecodeParameter(record.Value) // Decode the particle's value
+		if err != nil {                                        // Check for errors
+			return nil, err // Return the error
+		}
+
+		p := particle.NewParticle(net)   // Initialize the particle
+		p.Value = value                  // Set the particle's value
+		p.Steps = record.Steps           // Set the particle's steps
+		p.Complexity = record.Complexity // Set the particle's complexity
+		p.Age = record.Age               // Set the particle's age
+
+		particles[record.Vector] = p // Add the particle
+	}
+
+	return particles, nil // Return the particles
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,331 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dowlandaiello/eve/particle"
+)
+
+// DefaultChunkSize is the default length of each edge of the chunks in a
+// chunked store.
+const DefaultChunkSize = 8
+
+// ParticleStore is a store of the particles in a macrocosm, keyed by their
+// locations. Stores must be safe for concurrent use.
+type ParticleStore interface {
+	// Get gets the particle at the given vector, if any.
+	Get(vec Vector) (particle.Particle, bool)
+
+	// Put puts the given particle at the given vector, replacing any particle
+	// already there.
+	Put(vec Vector, p particle.Particle)
+
+	// Len gets the number of particles in the store.
+	Len() int
+
+	// Range calls the given callback with each of the particles in the store,
+	// sorted by location, until the callback returns false. The callback may
+	// modify the store.
+	Range(callback func(vec Vector, p particle.Particle) bool)
+
+	// Clear removes each of the particles from the store.
+	Clear()
+}
+
+// MapStore is a particle store holding each of its particles in a single map,
+// guarded by a single lock.
+type MapStore struct {
+	particles map[Vector]particle.Particle // the particles in the store
+
+	lock sync.RWMutex // the store's lock
+}
+
+// ChunkedStore is a particle store that divides space into fixed-size cubic
+// chunks, each guarded by its own lock, such that particles in different
+// chunks can be accessed concurrently.
+type ChunkedStore struct {
+	size int64 // the length of each edge of the store's chunks
+
+	chunks map[Vector]*chunk // the chunks in the store, keyed by the locations of their lowest corners divided by the chunk size
+	lock   sync.RWMutex      // the lock guarding the set of chunks
+
+	n int64 // the number of particles in the store
+}
+
+// chunk is a cube of space in a chunked store.
+type chunk struct {
+	particles []particle.Particle // the particles in the chunk, indexed by their offsets in the chunk
+	present   []bool              // whether or not there is a particle at each offset in the chunk
+
+	lock sync.RWMutex // the chunk's lock
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// NewMapStore initializes a new empty map store.
+func NewMapStore() *MapStore {
+	return &MapStore{
+		particles: make(map[Vector]particle.Particle), // Set the store's particles to an empty map of particles
+	} // Return the initialized store
+}
+
+// Get gets the particle at the given vector, if any.
+func (store *MapStore) Get(vec Vector) (particle.Particle, bool) {
+	store.lock.RLock()         // Lock the store
+	defer store.lock.RUnlock() // Unlock the store once done
+
+	p, ok := store.particles[vec] // Get the particle
+
+	return p, ok // Return the particle
+}
+
+// Put puts the given particle at the given vector.
+func (store *MapStore) Put(vec Vector, p particle.Particle) {
+	store.lock.Lock()         // Lock the store
+	defer store.lock.Unlock() // Unlock the store once done
+
+	store.particles[vec] = p // Put the particle
+}
+
+// Len gets the number of particles in the store.
+func (store *MapStore) Len() int {
+	store.lock.RLock()         // Lock the store
+	defer store.lock.RUnlock() // Unlock the store once done
+
+	return len(store.particles) // Return the number of particles
+}
+
+// Range calls the given callback with each of the particles in the store,
+// sorted by location, until the callback returns false.
+func (store *MapStore) Range(callback func(vec Vector, p particle.Particle) bool) {
+	store.lock.RLock() // Lock the store
+
+	vecs := make([]Vector, 0, len(store.particles))                       // Get a buffer to store the particles' locations in
+	particles := make(map[Vector]particle.Particle, len(store.particles)) // Get a buffer to copy the particles into
+
+	// Iterate through the particles
+	for vec, p := range store.particles {
+		vecs = append(vecs, vec) // Add the particle's location
+		particles[vec] = p       // Copy the particle
+	}
+
+	store.lock.RUnlock() // Unlock the store, so that the callback may modify it
+
+	// Sort the locations, so that the particles are always visited in the same order
+	sort.Slice(vecs, func(i, j int) bool {
+		return vecs[i].Less(vecs[j]) // Sort by location
+	})
+
+	// Iterate through the locations
+	for _, vec := range vecs {
+		// Check the callback should stop
+		if !callback(vec, particles[vec]) {
+			return // Stop iterating
+		}
+	}
+}
+
+// Clear removes each of the particles from the store.
+func (store *MapStore) Clear() {
+	store.lock.Lock()         // Lock the store
+	defer store.lock.Unlock() // Unlock the store once done
+
+	store.particles = make(map[Vector]particle.Particle) // Remove the particles
+}
+
+// NewChunkedStore initializes a new empty chunked store whose chunks have
+// edges of the given length. If the length isn't positive, the default chunk
+// size is used.
+func NewChunkedStore(size int64) *ChunkedStore {
+	// Check the size isn't positive
+	if size <= 0 {
+		size = DefaultChunkSize // Use the default chunk size
+	}
+
+	return &ChunkedStore{
+		size:   size,                    // Set the chunk size
+		chunks: make(map[Vector]*chunk), // Set the store's chunks to an empty map of chunks
+	} // Return the initialized store
+}
+
+// Get gets the particle at the given vector, if any.
+func (store *ChunkedStore) Get(vec Vector) (particle.Particle, bool) {
+	key, offset := store.locate(vec) // Get the chunk holding the vector
+
+	store.lock.RLock() // Lock the set of chunks
+
+	c, ok := store.chunks[key] // Get the chunk
+
+	store.lock.RUnlock() // Unlock the set of chunks
+
+	// Check the chunk doesn't exist
+	if !ok {
+		return particle.Particle{}, false // No particle at the vector
+	}
+
+	c.lock.RLock()         // Lock the chunk
+	defer c.lock.RUnlock() // Unlock the chunk once done
+
+	return c.particles[offset], c.present[offset] // Return the particle
+}
+
+// Put puts the given particle at the given vector.
+func (store *ChunkedStore) Put(vec Vector, p particle.Particle) {
+	key, offset := store.locate(vec) // Get the chunk holding the vector
+
+	c := store.chunk(key) // Get the chunk, creating it if necessary
+
+	c.lock.Lock()         // Lock the chunk
+	defer c.lock.Unlock() // Unlock the chunk once done
+
+	// Check there's no particle at the vector yet
+	if !c.present[offset] {
+		atomic.AddInt64(&store.n, 1) // Count the particle
+	}
+
+	c.particles[offset], c.present[offset] = p, true // Put the particle
+}
+
+// Len gets the number of particles in the store.
+func (store *ChunkedStore) Len() int {
+	return int(atomic.LoadInt64(&store.n)) // Return the number of particles
+}
+
+// Range calls the given callback with each of the particles in the store,
+// sorted by location, until the callback returns false. Each chunk is copied
+// before its particles are visited, such that the callback may modify the
+// store.
+func (store *ChunkedStore) Range(callback func(vec Vector, p particle.Particle) bool) {
+	store.lock.RLock() // Lock the set of chunks
+
+	keys := make([]Vector, 0, len(store.chunks))   // Get a buffer to store the chunks' keys in
+	chunks := make([]*chunk, 0, len(store.chunks)) // Get a buffer to store the chunks in
+
+	// Iterate through the chunks
+	for key, c := range store.chunks {
+		keys = append(keys, key)   // Add the chunk's key
+		chunks = append(chunks, c) // Add the chunk
+	}
+
+	store.lock.RUnlock() // Unlock the set of chunks
+
+	var visit []Vector                // Get a buffer to store the locations of the particles to visit in
+	var particles []particle.Particle // Get a buffer to store the particles to visit in
+
+	// Iterate through the chunks
+	for i, c := range chunks {
+		c.lock.RLock() // Lock the chunk
+
+		// Iterate through the chunk's offsets
+		for offset, present := range c.present {
+			// Check there's a particle at the offset
+			if present {
+				visit = append(visit, store.vectorAt(keys[i], int64(offset))) // Add the particle's location
+				particles = append(particles, c.particles[offset])            // Copy the particle
+			}
+		}
+
+		c.lock.RUnlock() // Unlock the chunk
+	}
+
+	order := make([]int, len(visit)) // Get a buffer to store the order in which the particles are visited in
+
+	// Iterate through the particles
+	for i := range order {
+		order[i] = i // Visit the particle in its original position
+	}
+
+	// Sort the particles, so that they are always visited in the same order
+	sort.Slice(order, func(i, j int) bool {
+		return visit[order[i]].Less(visit[order[j]]) // Sort by location
+	})
+
+	// Iterate through the particles
+	for _, i := range order {
+		// Check the callback should stop
+		if !callback(visit[i], particles[i]) {
+			return // Stop iterating
+		}
+	}
+}
+
+// Clear removes each of the particles from the store.
+func (store *ChunkedStore) Clear() {
+	store.lock.Lock()         // Lock the set of chunks
+	defer store.lock.Unlock() // Unlock the set of chunks once done
+
+	store.chunks = make(map[Vector]*chunk) // Remove the chunks
+	atomic.StoreInt64(&store.n, 0)         // Reset the number of particles
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// locate gets the key of the chunk holding the given vector, and the vector's
+// offset in the chunk.
+func (store *ChunkedStore) locate(vec Vector) (Vector, int64) {
+	key := NewVector(floorDiv(vec.X, store.size), floorDiv(vec.Y, store.size), floorDiv(vec.Z, store.size)) // Get the chunk's key
+
+	x, y, z := vec.X-key.X*store.size, vec.Y-key.Y*store.size, vec.Z-key.Z*store.size // Get the vector's position in the chunk
+
+	return key, (z*store.size+y)*store.size + x // Return the chunk's key and the vector's offset
+}
+
+// vectorAt gets the vector at the given offset in the chunk with the given
+// key.
+func (store *ChunkedStore) vectorAt(key Vector, offset int64) Vector {
+	x, y, z := offset%store.size, offset/store.size%store.size, offset/(store.size*store.size) // Get the position in the chunk
+
+	return NewVector(key.X*store.size+x, key.Y*store.size+y, key.Z*store.size+z) // Return the vector
+}
+
+// chunk gets the chunk with the given key, creating it if it doesn't exist.
+func (store *ChunkedStore) chunk(key Vector) *chunk {
+	store.lock.RLock() // Lock the set of chunks
+
+	c, ok := store.chunks[key] // Get the chunk
+
+	store.lock.RUnlock() // Unlock the set of chunks
+
+	// Check the chunk exists
+	if ok {
+		return c // Return the chunk
+	}
+
+	store.lock.Lock()         // Lock the set of chunks
+	defer store.lock.Unlock() // Unlock the set of chunks once done
+
+	// Check the chunk was created while the set of chunks was unlocked
+	if c, ok := store.chunks[key]; ok {
+		return c // Return the chunk
+	}
+
+	volume := store.size * store.size * store.size // Get the number of particles the chunk can hold
+
+	c = &chunk{
+		particles: make([]particle.Particle, volume), // Make room for the chunk's particles
+		present:   make([]bool, volume),              // Make room for the chunk's particles' presence
+	} // Initialize the chunk
+
+	store.chunks[key] = c // Add the chunk
+
+	return c // Return the chunk
+}
+
+// floorDiv divides a by b, rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b // Divide, rounding towards zero
+
+	// Check the quotient was rounded up
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q-- // Round down
+	}
+
+	return q // Return the quotient
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,116 @@
+package macrocosm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/juju/loggo"
+)
+
+// benchmarkRadii are the radii of the macrocosms polled and expanded by the
+// benchmarks.
+var benchmarkRadii = []int{1, 2, 4, 8}
+
+// benchmarkStores are the particle stores compared by the benchmarks.
+var benchmarkStores = []struct {
+	name string               // the name of the store
+	new  func() ParticleStore // initializes an empty store
+}{
+	{"map", func() ParticleStore { return NewMapStore() }},
+	{"chunked", func() ParticleStore { return NewChunkedStore(DefaultChunkSize) }},
+}
+
+// BenchmarkPoll measures the throughput of polling macrocosms of increasing
+// radius, in particles per second, with each of the particle stores.
+func BenchmarkPoll(b *testing.B) {
+	silenceLogs() // Keep the particles' evaluations from being logged
+
+	// Iterate through the radii
+	for _, radius := range benchmarkRadii {
+		radius := radius // Capture the radius
+
+		// Iterate through the stores
+		for _, store := range benchmarkStores {
+			store := store // Capture the store
+
+			b.Run(fmt.Sprintf("radius_%d/%s", radius, store.name), func(b *testing.B) {
+				var elapsed time.Duration // Get a buffer to store the time spent polling in
+				var polled int            // Get a buffer to store the number of particles polled in
+
+				// Poll a fresh macrocosm b.N times
+				for i := 0; i < b.N; i++ {
+					b.StopTimer() // Don't measure the expansion
+
+					macrocosm := newBenchmarkMacrocosm(store.new(), radius) // Expand a macrocosm to the radius
+
+					b.StartTimer() // Measure the poll
+
+					start := time.Now() // Get the time at which the poll started
+
+					macrocosm.Poll() // Poll the macrocosm
+
+					elapsed += time.Since(start)        // Count the time spent polling
+					polled += macrocosm.Particles.Len() // Count the polled particles
+				}
+
+				b.ReportMetric(float64(polled)/elapsed.Seconds(), "particles/s") // Report the throughput
+			}) // Benchmark the store at the radius
+		}
+	}
+}
+
+// BenchmarkExpand measures the throughput of expanding macrocosms to
+// increasing radii, in particles per second, with each of the particle
+// stores.
+func BenchmarkExpand(b *testing.B) {
+	silenceLogs() // Keep the expansions from being logged
+
+	// Iterate through the radii
+	for _, radius := range benchmarkRadii {
+		radius := radius // Capture the radius
+
+		// Iterate through the stores
+		for _, store := range benchmarkStores {
+			store := store // Capture the store
+
+			b.Run(fmt.Sprintf("radius_%d/%s", radius, store.name), func(b *testing.B) {
+				var generated int // Get a buffer to store the number of generated particles in
+
+				start := time.Now() // Get the time at which the expansions started
+
+				// Expand a fresh macrocosm b.N times
+				for i := 0; i < b.N; i++ {
+					macrocosm := newBenchmarkMacrocosm(store.new(), radius) // Expand a macrocosm to the radius
+
+					generated += macrocosm.Particles.Len() // Count the generated particles
+				}
+
+				b.ReportMetric(float64(generated)/time.Since(start).Seconds(), "particles/s") // Report the throughput
+			}) // Benchmark the store at the radius
+		}
+	}
+}
+
+// newBenchmarkMacrocosm initializes a new macrocosm storing its particles in
+// the given store, and expands it to the given radius.
+func newBenchmarkMacrocosm(store ParticleStore, radius int) *Macrocosm {
+	macrocosm := NewMacrocosm(1) // Initialize the macrocosm
+	macrocosm.Particles = store  // Use the store
+
+	macrocosm.Expand() // Generate the root particle
+
+	loggo.ConfigureLoggers(fmt.Sprintf("macrocosm_%d=INFO", macrocosm.Identifier)) // Skip formatting the debug logs
+
+	// Expand the macrocosm to the radius
+	for i := 0; i < radius; i++ {
+		macrocosm.Expand() // Expand the macrocosm
+	}
+
+	return &macrocosm // Return the macrocosm
+}
+
+// silenceLogs discards each of the logs written while benchmarking.
+func silenceLogs() {
+	loggo.RemoveWriter("default") // Discard the logs
+}
@@ -0,0 +1,108 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import (
+	"encoding/json"
+
+	"github.com/dowlandaiello/eve/activation"
+)
+
+// ParticleTrace is a trace of a single evaluation of a particle.
+type ParticleTrace struct {
+	Tick int64 // the tick during which the particle was evaluated
+
+	Vector Vector // the location of the particle
+
+	Inputs []activation.Parameter // the parameters passed into the particle's root nodes
+
+	Steps []activation.TraceStep // each of the steps taken by the evaluation
+
+	Output activation.Parameter // the output of the particle's net
+
+	Killed bool // whether or not the particle was killed as a result of the evaluation
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// Trace enables tracing of the particle at the given vector. Each subsequent
+// evaluation of the particle is traced, until tracing is disabled.
+func (macrocosm *Macrocosm) Trace(vec Vector) {
+	macrocosm.Lock.Lock()         // Lock the macrocosm
+	defer macrocosm.Lock.Unlock() // Unlock the macrocosm once done
+
+	// Check no particles have been traced yet
+	if macrocosm.traced == nil {
+		macrocosm.traced = make(map[Vector]bool) // Initialize the set of traced particles
+	}
+
+	macrocosm.traced[vec] = true // Trace the particle
+}
+
+// Untrace disables tracing of the particle at the given vector, and discards
+// its most recent trace.
+func (macrocosm *Macrocosm) Untrace(vec Vector) {
+	macrocosm.Lock.Lock()         // Lock the macrocosm
+	defer macrocosm.Lock.Unlock() // Unlock the macrocosm once done
+
+	delete(macrocosm.traced, vec) // Stop tracing the particle
+	delete(macrocosm.traces, vec) // Discard the particle's trace
+}
+
+// ParticleTrace gets the trace of the most recent evaluation of the particle
+// at the given vector, if the particle was traced.
+func (macrocosm *Macrocosm) ParticleTrace(vec Vector) (ParticleTrace, bool) {
+	macrocosm.Lock.RLock()         // Lock the macrocosm
+	defer macrocosm.Lock.RUnlock() // Unlock the macrocosm once done
+
+	trace, ok := macrocosm.traces[vec] // Get the particle's trace
+
+	return trace, ok // Return the trace
+}
+
+// MarshalJSON marshals the given trace to a JSON byte slice.
+func (trace *ParticleTrace) MarshalJSON() ([]byte, error) {
+	type alias ParticleTrace // Prevent the marshaller from recursing
+
+	return json.Marshal((*alias)(trace)) // Marshal the trace to JSON
+}
+
+// UnmarshalParticleTraceJSON unmarshals a trace from a given JSON byte slice.
+func UnmarshalParticleTraceJSON(b []byte) (*ParticleTrace, error) {
+	var trace ParticleTrace // The unmarshalled trace
+
+	err := json.Unmarshal(b, &trace) // Unmarshal the JSON into a trace
+	if err != nil {                  // Check for errors
+		return nil, err // Return the error
+	}
+
+	return &trace, nil // Return the trace
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// isTraced checks whether or not the particle at the given vector is being
+// traced.
+func (macrocosm *Macrocosm) isTraced(vec Vector) bool {
+	macrocosm.Lock.RLock()         // Lock the macrocosm
+	defer macrocosm.Lock.RUnlock() // Unlock the macrocosm once done
+
+	return macrocosm.traced[vec] // Return whether or not the particle is traced
+}
+
+// recordTrace stores the given trace as the most recent trace of its
+// particle.
+func (macrocosm *Macrocosm) recordTrace(trace ParticleTrace) {
+	macrocosm.Lock.Lock()         // Lock the macrocosm
+	defer macrocosm.Lock.Unlock() // Unlock the macrocosm once done
+
+	// Check no traces have been recorded yet
+	if macrocosm.traces == nil {
+		macrocosm.traces = make(map[Vector]ParticleTrace) // Initialize the set of traces
+	}
+
+	macrocosm.traces[trace.Vector] = trace // Record the trace
+}
+
+/* END INTERNAL METHODS */
@@ -0,0 +1,57 @@
+// Package macrocosm implements the entirety of an eve simulation.
+package macrocosm
+
+import "errors"
+
+// ErrUnknownUpdateMode is an error definition describing an update mode name
+// that doesn't correspond to any update mode.
+var ErrUnknownUpdateMode = errors.New("unknown update mode")
+
+// UpdateMode represents the way in which the particles evaluated during a
+// poll see each other's new values.
+type UpdateMode int
+
+const (
+	// SynchronousUpdate is an update mode in which each particle reads the
+	// values of its neighbors as of the start of the tick. The new state of
+	// each particle is buffered until every particle has been evaluated, such
+	// that the outcome of a tick doesn't depend on the order in which the
+	// particles are evaluated. Only values are buffered: the liveness of the
+	// particles' nets is updated in place (see Macrocosm.Poll).
+	SynchronousUpdate UpdateMode = iota
+
+	// AsynchronousUpdate is an update mode in which the new state of each
+	// particle is written back as soon as it has been evaluated, such that
+	// neighbors evaluated later in the same tick may read it. Since particles
+	// are evaluated concurrently, the outcome of a tick depends on goroutine
+	// scheduling.
+	AsynchronousUpdate
+)
+
+// updateModeNames maps each update mode to its textual representation.
+var updateModeNames = map[UpdateMode]string{
+	SynchronousUpdate:  "synchronous",
+	AsynchronousUpdate: "asynchronous",
+}
+
+/* BEGIN EXPORTED METHODS */
+
+// ParseUpdateMode gets the update mode with the given name.
+func ParseUpdateMode(name string) (UpdateMode, error) {
+	// Iterate through the named update modes
+	for mode, modeName := range updateModeNames {
+		// Check the names match
+		if modeName == name {
+			return mode, nil // Return the update mode
+		}
+	}
+
+	return SynchronousUpdate, ErrUnknownUpdateMode // Return the error
+}
+
+// String gets the textual representation of the update mode.
+func (mode UpdateMode) String() string {
+	return updateModeNames[mode] // Return the name of the update mode
+}
+
+/* END EXPORTED METHODS */
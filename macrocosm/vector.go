@@ -2,8 +2,8 @@
 package macrocosm
 
 import (
+	"context"
 	"math"
-	"sync"
 )
 
 // Axis is an integer type alias representing a 3d axis.
@@ -94,40 +94,9 @@ func VectorsBetween(a, b Vector) []Vector {
 }
 
 // DoForVectorsBetween runs a given callback for each of the vectors between
-// points a (inclusive) and b (inclusive).
+// points a (inclusive) and b (inclusive) on the default worker pool.
 func DoForVectorsBetween(a, b Vector, callback func(vec Vector)) {
-	var wg sync.WaitGroup // Get a wait group
-
-	distance := b.Sub(a)                                // Get the distance between the points
-	absDistance := distance.Abs()                       // Get the absolute value of the distance
-	realDistance := absDistance.Add(NewVector(1, 1, 1)) // Add one to the final distance
-
-	wg.Add(int(realDistance.Product())) // Make enough wait groups for the number of nodes in between a and b
-
-	mag := a.Magnitude(b) // Get the magnitude for b
-
-	zCondition := mag.deriveMagnitudeCondition(Z) // Derive a magnitude condition
-
-	// Make the amount of z groups in between x and y
-	for z := a.Z; zCondition(z, b.Z); z += mag.Z {
-		yCondition := mag.deriveMagnitudeCondition(Y) // Derive a magnitude condition
-
-		// Make the amount of y groups in between x and y
-		for y := a.Y; yCondition(y, b.Y); y += mag.Y {
-			xCondition := mag.deriveMagnitudeCondition(X) // Derive a magnitude condition
-
-			// Do the same for the x values
-			for x := a.X; xCondition(x, b.X); x += mag.X {
-				go func(x, y, z int64, wg *sync.WaitGroup) {
-					callback(NewVector(x, y, z)) // Run the callback with the vector
-
-					wg.Done() // Done!
-				}(x, y, z, &wg) // Pass the coordinates and wait group into the goroutine
-			}
-		}
-	}
-
-	wg.Wait() // Wait for all of the callbacks to terminate
+	ForVectorsBetween(context.Background(), PooledExecution, nil, a, b, callback) // Run the callbacks
 }
 
 // Values gets a slice of the vector's values.
@@ -223,6 +192,23 @@ func (vector *Vector) Corner(upper bool) Vector {
 	return vector.Sub(NewVector(1, 1, 1)) // Return the lower
 }
 
+// Less checks whether or not the vector is ordered before the given vector.
+// Vectors are ordered by their z values, then their y values, and then their
+// x values.
+func (vector *Vector) Less(vec Vector) bool {
+	// Check the z values differ
+	if vector.Z != vec.Z {
+		return vector.Z < vec.Z // Order by z value
+	}
+
+	// Check the y values differ
+	if vector.Y != vec.Y {
+		return vector.Y < vec.Y // Order by y value
+	}
+
+	return vector.X < vec.X // Order by x value
+}
+
 // CornersAtParamCount gets the required vectors to satisfy a parameter count.
 func (vector *Vector) CornersAtParamCount(numParams int) (Vector, Vector) {
 	return vector.cornersAtParamCount(*vector, *vector, numParams, 0, 1) // Return the final corners
@@ -12,6 +12,14 @@ type Particle struct {
 	Net activation.Net // the particle's net
 
 	Value activation.Parameter // the value of the particle
+
+	Steps int // the number of steps taken by the particle's most recent evaluation (its operating functional complexity)
+
+	Complexity int // the functional complexity of the particle's net, as of its most recent evaluation
+
+	Age int // the number of times the particle has been evaluated
+
+	program *activation.Program // the particle's compiled net (nil if it hasn't been compiled)
 }
 
 /* BEGIN EXPORTED METHODS */
@@ -23,11 +31,11 @@ func NewParticle(net activation.Net) Particle {
 	} // Return the initialized particle
 }
 
-// RandomParticle initializes a new random particle with the given
-// initialization options.
-func RandomParticle(opts ...InitializationOption) Particle {
+// RandomParticle initializes a new random particle from the given environment
+// with the given initialization options.
+func RandomParticle(env *activation.Environment, opts ...InitializationOption) Particle {
 	particle := Particle{
-		Net: activation.RandomNet(), // Set the particle's net to a random activation net
+		Net: activation.RandomNet(env), // Set the particle's net to a random activation net
 	} // Initialize a particle
 
 	// Iterate through the provided options
@@ -38,6 +46,18 @@ func RandomParticle(opts ...InitializationOption) Particle {
 	return particle // Return the final particle
 }
 
+// Output evaluates the particle's net in the given environment. The net is
+// compiled on its first evaluation, and recompiled only once it has been
+// replaced (e.g. by mutation, or the application of an injection).
+func (particle *Particle) Output(env *activation.Environment, params ...activation.Parameter) activation.Parameter {
+	// Check the net hasn't been compiled, or has been replaced since
+	if particle.program == nil || !particle.program.Compiled(&particle.Net) {
+		particle.program = activation.Compile(&particle.Net) // Compile the net
+	}
+
+	return particle.program.Output(env, params...) // Evaluate the compiled net
+}
+
 // NumAliveNodes gets the number of alive nodes pertaining to the particle.
 func (particle *Particle) NumAliveNodes() int {
 	i := 0 // Get a counter to increment for each of the root nodes
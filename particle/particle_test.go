@@ -0,0 +1,41 @@
+package particle
+
+import (
+	"testing"
+
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/activation/mutation"
+)
+
+// TestOutputReusesProgram checks that a particle's compiled net is reused
+// across ticks in which its net isn't mutated, and rebuilt once it is.
+func TestOutputReusesProgram(t *testing.T) {
+	// Iterate through the seeds of the particles to evaluate
+	for seed := int64(0); seed < 100; seed++ {
+		env := activation.NewEnvironment(seed) // Get an environment to draw from
+
+		p := RandomParticle(env) // Generate a random particle
+
+		p.Output(env, activation.Parameter{I: 1}) // Compile and evaluate the particle's net
+
+		compiled := p.program // Get the compiled net
+
+		p.Net = mutation.Mutate(env, p.Net, mutation.UniformRates(1e-12)) // Mutate the net at rates low enough that no operator is applied
+
+		p.Output(env, activation.Parameter{I: 1}) // Evaluate the particle's net again
+
+		// Check the net was recompiled
+		if p.program != compiled {
+			t.Fatalf("seed %d: particle recompiled across a tick in which its net wasn't mutated", seed) // Fail
+		}
+
+		p.Net = mutation.Mutate(env, p.Net, mutation.UniformRates(1)) // Mutate the net at rates high enough that every operator is applied
+
+		p.Output(env, activation.Parameter{I: 1}) // Evaluate the particle's net again
+
+		// Check the net wasn't recompiled
+		if len(p.Net.RootNodes) > 0 && p.program == compiled {
+			t.Fatalf("seed %d: particle not recompiled after its net was mutated", seed) // Fail
+		}
+	}
+}
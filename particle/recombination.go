@@ -0,0 +1,205 @@
+// Package particle implements an eve particle.
+package particle
+
+import (
+	"math/rand"
+
+	"github.com/dowlandaiello/eve/activation"
+	"github.com/dowlandaiello/eve/activation/mutation"
+)
+
+// RecombinationStrategy represents a method of combining the nets of two
+// particles.
+type RecombinationStrategy int
+
+const (
+	// UniformCrossover is a strategy in which each of the offspring's root
+	// nodes is taken from a random parent.
+	UniformCrossover RecombinationStrategy = iota
+
+	// SubtreeSwap is a strategy in which the destination of a random link in
+	// the first parent is replaced with a random node from the second
+	// parent.
+	SubtreeSwap
+
+	// SinglePointCrossover is a strategy in which the nodes of each parent
+	// are linearized in depth-first order, and the offspring takes the shape
+	// of the first parent, with the functions of each node past a random
+	// point taken from the second parent.
+	SinglePointCrossover
+)
+
+/* BEGIN EXPORTED METHODS */
+
+// Recombine produces an offspring of the two given particles, drawing from
+// the given environment (whose operations supply any functions the offspring
+// is given). If multiple strategies are provided, one of
+// them is picked at random; if none are provided, one of all of the
+// strategies is picked. Both parents are left untouched, and the offspring's
+// net is well-formed: each of its links and non-root nodes are alive, each of
+// its nodes has an initialized function, and each of its links has a valid
+// condition. Root nodes that are dead in the parent they were taken from stay
+// dead.
+func Recombine(a, b Particle, env *activation.Environment, strategies ...RecombinationStrategy) Particle {
+	r := env.Rand // Get the source of randomness to draw from
+
+	// Check no strategies were provided
+	if len(strategies) == 0 {
+		strategies = []RecombinationStrategy{UniformCrossover, SubtreeSwap, SinglePointCrossover} // Pick from all of the strategies
+	}
+
+	var net activation.Net // Declare a buffer to store the offspring's net in
+
+	// Handle the different strategies
+	switch strategies[r.Intn(len(strategies))] {
+	case SubtreeSwap:
+		net = subtreeSwap(a.Net, b.Net, r) // Swap a subtree
+	case SinglePointCrossover:
+		net = singlePointCrossover(a.Net, b.Net, r) // Cross over at a single point
+	default:
+		net = uniformCrossover(a.Net, b.Net, r) // Cross over each of the root nodes
+	}
+
+	revive(&net, env) // Make sure the offspring's net is well-formed
+
+	return NewParticle(net) // Return the offspring
+}
+
+/* END EXPORTED METHODS */
+
+/* BEGIN INTERNAL METHODS */
+
+// uniformCrossover takes each root node from a random parent. The offspring
+// has as many root nodes as a random parent; where only one parent has a root
+// node at a given index, that parent's root node is taken.
+func uniformCrossover(a, b activation.Net, r *rand.Rand) activation.Net {
+	n := len(a.RootNodes) // Get the number of root nodes in the offspring
+
+	// Check the offspring should be as large as the second parent
+	if r.Intn(2) == 0 {
+		n = len(b.RootNodes) // Use the second parent's size
+	}
+
+	offspring := activation.Net{Reducer: a.Reducer, LinkMode: a.LinkMode, Combiner: a.Combiner} // Initialize the offspring's net
+
+	// Make each of the root nodes
+	for i := 0; i < n; i++ {
+		parent := &a // Get the parent to take the root node from
+
+		// Check the second parent should be used
+		if i >= len(a.RootNodes) || (i < len(b.RootNodes) && r.Intn(2) == 0) {
+			parent = &b // Use the second parent
+		}
+
+		offspring.RootNodes = append(offspring.RootNodes, parent.RootNodes[i].Clone()) // Add the root node
+	}
+
+	return offspring // Return the offspring's net
+}
+
+// subtreeSwap replaces the destination of a random link in the first parent
+// with a random node from the second parent. If either parent has no
+// candidates, a uniform crossover is performed instead.
+func subtreeSwap(a, b activation.Net, r *rand.Rand) activation.Net {
+	offspring := a.Clone() // Copy the first parent
+
+	var links []*activation.ConditionalLink // Get a buffer to store the links that could be rewired in
+
+	// Iterate through the nodes in the offspring
+	for _, node := range mutation.Nodes(&offspring) {
+		// Iterate through the node's links
+		for i := range node.Links {
+			// Check the link has a destination
+			if node.Links[i].HasDestination() {
+				links = append(links, &node.Links[i]) // Add the link
+			}
+		}
+	}
+
+	donors := mutation.Nodes(&b) // Get the nodes in the second parent
+
+	// Check there's nothing to swap
+	if len(links) == 0 || len(donors) == 0 {
+		return uniformCrossover(a, b, r) // Fall back to a uniform crossover
+	}
+
+	link := links[r.Intn(len(links))] // Get the link to swap the destination of
+
+	link.Destination = donors[r.Intn(len(donors))].Clone() // Swap the destination
+
+	return offspring // Return the offspring's net
+}
+
+// singlePointCrossover takes the shape of the first parent, and the functions
+// of each of its linearized nodes past a random point from the second parent.
+func singlePointCrossover(a, b activation.Net, r *rand.Rand) activation.Net {
+	offspring := a.Clone() // Copy the first parent
+
+	nodes := mutation.Nodes(&offspring) // Linearize the offspring
+	donors := mutation.Nodes(&b)        // Linearize the second parent
+
+	n := len(nodes) // Get the number of nodes whose functions can be taken from the second parent
+
+	// Check the second parent is smaller
+	if len(donors) < n {
+		n = len(donors) // Only take as many functions as the second parent has
+	}
+
+	// Check there's nothing to cross over
+	if n == 0 {
+		return offspring // Return the copy of the first parent
+	}
+
+	// Iterate through the nodes past the crossover point
+	for i := r.Intn(n); i < n; i++ {
+		nodes[i].Function = donors[i].Function.Clone() // Take the function from the second parent
+	}
+
+	return offspring // Return the offspring's net
+}
+
+// revive makes the given net well-formed by bringing each of its links and
+// non-root nodes back to life, replacing invalid conditions with the
+// unconditional condition, and replacing uninitialized functions with random
+// functions drawn from the given environment's operations. Root nodes killed
+// by selection (e.g. by decay) are left dead.
+func revive(net *activation.Net, env *activation.Environment) {
+	// Iterate through the root nodes
+	for i := range net.RootNodes {
+		reviveNode(&net.RootNodes[i], env, true) // Revive the root node's subtree
+	}
+}
+
+// reviveNode revives the given node and each of the nodes in its subtree,
+// drawing random functions from the given environment. Leaf nodes and nodes
+// with uninitialized functions are visited too, unlike in mutation.Nodes.
+func reviveNode(node *activation.Node, env *activation.Environment, root bool) {
+	// Check the node isn't a root node
+	if !root {
+		node.Alive = true // Bring the node back to life
+	}
+
+	// Replace the node's function until it's initialized
+	for node.Function.IsZero() {
+		node.Function = activation.RandomComputation(env) // Replace the function
+	}
+
+	// Iterate through the node's links
+	for i := range node.Links {
+		link := &node.Links[i] // Get the link
+
+		link.Alive = true // Bring the link back to life
+
+		// Check the condition is invalid
+		if link.Condition < activation.EqualTo || link.Condition > activation.Unconditional {
+			link.Condition = activation.Unconditional // Replace the condition
+		}
+
+		// Check the link has a destination
+		if !link.Destination.IsNone() {
+			reviveNode(&link.Destination, env, false) // Revive the destination's subtree
+		}
+	}
+}
+
+/* END INTERNAL METHODS */